@@ -3,12 +3,20 @@ package batcha
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	awsconfig "github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials/stscreds"
 	"github.com/aws/aws-sdk-go-v2/service/batch"
 	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/fujiwara/tfstate-lookup/tfstate"
 	goconfig "github.com/kayac/go-config"
 )
@@ -20,37 +28,232 @@ var Version = "dev"
 type App struct {
 	config     *Config
 	configPath string
+	// definition is a pre-rendered job definition (from a bundle or
+	// register --from-rendered) used instead of rendering the template.
+	definition map[string]any
+	// renderCache caches renders when enabled by EnableRenderCache.
+	renderCache *renderCache
+
+	// stdout and stderr receive the output of register (os.Stdout and
+	// os.Stderr when nil). RegisterAll buffers them per config.
+	stdout io.Writer
+	stderr io.Writer
+
+	// batchClients overrides the AWS Batch client per region (used by tests).
+	batchClients map[string]batchAPI
+	// logsClient overrides the CloudWatch Logs client (used by tests).
+	logsClient logsAPI
+}
+
+// batchAPI is the subset of the AWS Batch API used by batcha.
+type batchAPI interface {
+	DescribeJobDefinitions(ctx context.Context, params *batch.DescribeJobDefinitionsInput, optFns ...func(*batch.Options)) (*batch.DescribeJobDefinitionsOutput, error)
+	RegisterJobDefinition(ctx context.Context, params *batch.RegisterJobDefinitionInput, optFns ...func(*batch.Options)) (*batch.RegisterJobDefinitionOutput, error)
+	DeregisterJobDefinition(ctx context.Context, params *batch.DeregisterJobDefinitionInput, optFns ...func(*batch.Options)) (*batch.DeregisterJobDefinitionOutput, error)
+	SubmitJob(ctx context.Context, params *batch.SubmitJobInput, optFns ...func(*batch.Options)) (*batch.SubmitJobOutput, error)
+	DescribeJobs(ctx context.Context, params *batch.DescribeJobsInput, optFns ...func(*batch.Options)) (*batch.DescribeJobsOutput, error)
+	ListJobs(ctx context.Context, params *batch.ListJobsInput, optFns ...func(*batch.Options)) (*batch.ListJobsOutput, error)
+	DescribeJobQueues(ctx context.Context, params *batch.DescribeJobQueuesInput, optFns ...func(*batch.Options)) (*batch.DescribeJobQueuesOutput, error)
 }
 
 // New creates a new App by loading the config file.
 func New(ctx context.Context, configPath string) (*App, error) {
-	cfg, err := LoadConfig(configPath)
+	cfg, err := LoadConfig(ctx, configPath)
 	if err != nil {
 		return nil, err
 	}
+	if cfg.usesSSM() {
+		awsCfg, err := loadAWSConfig(ctx, cfg)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load AWS config: %w", err)
+		}
+		if err := resolveFromSSM(ctx, cfg, ssm.NewFromConfig(awsCfg)); err != nil {
+			return nil, err
+		}
+	}
 	return &App{config: cfg, configPath: configPath}, nil
 }
 
+// out returns the writer for command output.
+func (app *App) out() io.Writer {
+	if app.stdout != nil {
+		return app.stdout
+	}
+	return os.Stdout
+}
+
+// errOut returns the writer for diagnostics such as hook output.
+func (app *App) errOut() io.Writer {
+	if app.stderr != nil {
+		return app.stderr
+	}
+	return os.Stderr
+}
+
 // newBatchClient creates an AWS Batch client from the app's config region.
-func (app *App) newBatchClient(ctx context.Context) (*batch.Client, error) {
-	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(app.config.Region))
+func (app *App) newBatchClient(ctx context.Context) (batchAPI, error) {
+	if client, ok := app.batchClients[app.config.Region]; ok {
+		return client, nil
+	}
+	awsCfg, err := loadAWSConfig(ctx, app.config)
 	if err != nil {
 		return nil, err
 	}
 	return batch.NewFromConfig(awsCfg), nil
 }
 
-// setupPlugins configures the go-config loader with tfstate FuncMaps.
-func setupPlugins(ctx context.Context, cfg *Config, loader *goconfig.Loader) error {
-	for _, p := range cfg.Plugins {
-		if p.Name != "tfstate" {
+// logsAPI is the subset of the CloudWatch Logs API used by batcha.
+type logsAPI interface {
+	GetLogEvents(ctx context.Context, params *cloudwatchlogs.GetLogEventsInput, optFns ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetLogEventsOutput, error)
+}
+
+// forRegion returns a copy of the app that targets the given region.
+func (app *App) forRegion(region string) *App {
+	cfg := *app.config
+	cfg.setRegion(region)
+	c := *app
+	c.config = &cfg
+	return &c
+}
+
+// eachRegion runs fn once per configured region. With a single region fn
+// runs on the app itself; with several, every region is attempted and the
+// failures are aggregated.
+func (app *App) eachRegion(fn func(app *App) error) error {
+	if len(app.config.Regions) <= 1 {
+		return fn(app)
+	}
+	var (
+		errs  []error
+		diffs int
+	)
+	for _, rc := range app.config.Regions {
+		region := rc.Region
+		fmt.Fprintf(app.out(), "==> %s\n", region)
+		err := fn(app.forRegion(region))
+		if err == nil {
 			continue
 		}
-		funcMap, err := tfstate.FuncMap(ctx, p.Config.URL)
-		if err != nil {
-			return fmt.Errorf("failed to load tfstate from %s: %w", p.Config.URL, err)
+		if _, ok := err.(*DiffError); ok {
+			diffs++
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", region, err))
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if diffs > 0 {
+		return &DiffError{}
+	}
+	return nil
+}
+
+// newLogsClient creates a CloudWatch Logs client from the app's config region.
+func (app *App) newLogsClient(ctx context.Context) (logsAPI, error) {
+	if app.logsClient != nil {
+		return app.logsClient, nil
+	}
+	awsCfg, err := loadAWSConfig(ctx, app.config)
+	if err != nil {
+		return nil, err
+	}
+	return cloudwatchlogs.NewFromConfig(awsCfg), nil
+}
+
+// loadAWSConfig loads the AWS SDK config for cfg.Region and applies the
+// credentials selected by the config.
+func loadAWSConfig(ctx context.Context, cfg *Config) (aws.Config, error) {
+	opts := []func(*awsconfig.LoadOptions) error{awsconfig.WithRegion(cfg.Region)}
+	if profile := cfg.profile(); profile != "" {
+		opts = append(opts, awsconfig.WithSharedConfigProfile(profile))
+	}
+	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return aws.Config{}, err
+	}
+	if provider := credentialsProvider(cfg, sts.NewFromConfig(awsCfg)); provider != nil {
+		awsCfg.Credentials = aws.NewCredentialsCache(provider)
+	}
+	return awsCfg, nil
+}
+
+// credentialsProvider selects the credentials provider configured in cfg.
+// Precedence: web identity > assume role > default chain (nil). The role is
+// the active region's assume_role_arn, falling back to role_arn.
+func credentialsProvider(cfg *Config, stsClient *sts.Client) aws.CredentialsProvider {
+	roleARN := cfg.roleARN()
+	switch {
+	case cfg.WebIdentityTokenFile != "" && roleARN != "":
+		return stscreds.NewWebIdentityRoleProvider(
+			stsClient,
+			roleARN,
+			stscreds.IdentityTokenFile(cfg.WebIdentityTokenFile),
+			func(o *stscreds.WebIdentityRoleOptions) {
+				o.RoleSessionName = "batcha"
+			},
+		)
+	case roleARN != "":
+		return stscreds.NewAssumeRoleProvider(stsClient, roleARN, func(o *stscreds.AssumeRoleOptions) {
+			o.RoleSessionName = "batcha"
+		})
+	}
+	return nil
+}
+
+// jobDefinitionName renders the template and returns its jobDefinitionName.
+func (app *App) jobDefinitionName(ctx context.Context) (string, error) {
+	rendered, err := app.render(ctx)
+	if err != nil {
+		return "", err
+	}
+	converted := walkMap(rendered, toPascalCase)
+	name, _ := converted.(map[string]any)["JobDefinitionName"].(string)
+	if name == "" {
+		return "", fmt.Errorf("jobDefinitionName is required in job definition")
+	}
+	return name, nil
+}
+
+// definitionName returns name when it is set (--no-render), otherwise the
+// jobDefinitionName of the rendered template.
+func (app *App) definitionName(ctx context.Context, name string) (string, error) {
+	if name != "" {
+		return name, nil
+	}
+	return app.jobDefinitionName(ctx)
+}
+
+// setupPlugins configures the go-config loader with the tfstate and ssm
+// FuncMaps.
+func setupPlugins(ctx context.Context, cfg *Config, loader *goconfig.Loader) error {
+	for _, p := range cfg.Plugins {
+		switch p.Name {
+		case "tfstate":
+			funcMap, err := tfstate.FuncMap(ctx, p.Config.URL)
+			if err != nil {
+				return fmt.Errorf("failed to load tfstate from %s: %w", p.Config.URL, err)
+			}
+			loader.Funcs(funcMap)
+		case "ssm":
+			awsCfg, err := loadAWSConfig(ctx, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to load AWS config for ssm plugin: %w", err)
+			}
+			loader.Funcs(ssmFuncMap(ctx, ssm.NewFromConfig(awsCfg)))
+		case "cfn":
+			awsCfg, err := loadAWSConfig(ctx, cfg)
+			if err != nil {
+				return fmt.Errorf("failed to load AWS config for cfn plugin: %w", err)
+			}
+			loader.Funcs(cfnFuncMap(ctx, cloudformation.NewFromConfig(awsCfg)))
+		case "exec":
+			funcMap, err := execFuncMap(ctx, p.Config)
+			if err != nil {
+				return fmt.Errorf("exec plugin: %w", err)
+			}
+			loader.Funcs(funcMap)
 		}
-		loader.Funcs(funcMap)
 	}
 	return nil
 }
@@ -66,6 +269,35 @@ func pickLatestRevision(defs []batchTypes.JobDefinition) batchTypes.JobDefinitio
 	return latest
 }
 
+// describeRevisions fetches the given revisions of a job definition by
+// name:revision regardless of their status. Revisions that do not exist are
+// returned in missing.
+func describeRevisions(ctx context.Context, client batchAPI, name string, revisions []int32) (found map[int32]batchTypes.JobDefinition, missing []int32, err error) {
+	ids := make([]string, len(revisions))
+	for i, r := range revisions {
+		ids[i] = fmt.Sprintf("%s:%d", name, r)
+	}
+	found = make(map[int32]batchTypes.JobDefinition, len(revisions))
+	paginator := batch.NewDescribeJobDefinitionsPaginator(client, &batch.DescribeJobDefinitionsInput{
+		JobDefinitions: ids,
+	})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to describe job definitions: %w", err)
+		}
+		for _, d := range out.JobDefinitions {
+			found[aws.ToInt32(d.Revision)] = d
+		}
+	}
+	for _, r := range revisions {
+		if _, ok := found[r]; !ok {
+			missing = append(missing, r)
+		}
+	}
+	return found, missing, nil
+}
+
 // normalizeRemoteDefinition converts an AWS job definition to a comparable
 // map by stripping AWS-managed fields.
 func normalizeRemoteDefinition(def batchTypes.JobDefinition) (map[string]any, error) {
@@ -80,5 +312,23 @@ func normalizeRemoteDefinition(def batchTypes.JobDefinition) (map[string]any, er
 	for _, key := range initExcludeKeys {
 		delete(m, key)
 	}
+	deleteFingerprintTag(m)
 	return m, nil
 }
+
+// deleteFingerprintTag removes the tag set by register --fingerprint-tag from
+// a PascalCase definition, and Tags with it when no other tag is left. The
+// tag is metadata about the registration, not part of the template.
+func deleteFingerprintTag(def map[string]any) {
+	tags, ok := def["Tags"].(map[string]any)
+	if !ok {
+		return
+	}
+	if _, ok := tags[fingerprintTagKey]; !ok {
+		return
+	}
+	delete(tags, fingerprintTagKey)
+	if len(tags) == 0 {
+		delete(def, "Tags")
+	}
+}
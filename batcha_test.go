@@ -1,10 +1,15 @@
 package batcha
 
 import (
+	"context"
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
 	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
 )
 
 func TestNormalizeRemoteDefinition(t *testing.T) {
@@ -61,3 +66,115 @@ func TestPickLatestRevision(t *testing.T) {
 		t.Errorf("expected revision 3, got %d", aws.ToInt32(latest.Revision))
 	}
 }
+
+func TestCredentialsProvider(t *testing.T) {
+	stsClient := sts.New(sts.Options{Region: "us-east-1"})
+
+	tests := []struct {
+		name string
+		cfg  Config
+		want string
+	}{
+		{
+			name: "web_identity",
+			cfg:  Config{RoleARN: "arn:aws:iam::123456789012:role/ci", WebIdentityTokenFile: "/tmp/token"},
+			want: "*stscreds.WebIdentityRoleProvider",
+		},
+		{
+			name: "assume_role",
+			cfg:  Config{RoleARN: "arn:aws:iam::123456789012:role/deploy"},
+			want: "*stscreds.AssumeRoleProvider",
+		},
+		{
+			name: "default_chain",
+			cfg:  Config{},
+			want: "<nil>",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := fmt.Sprintf("%T", credentialsProvider(&tt.cfg, stsClient))
+			if got != tt.want {
+				t.Errorf("credentialsProvider() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeBatchClient implements batchAPI for tests. Methods without a func
+// field set panic via the embedded nil interface.
+type fakeBatchClient struct {
+	batchAPI
+
+	describeJobDefinitions  func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error)
+	registerJobDefinition   func(*batch.RegisterJobDefinitionInput) (*batch.RegisterJobDefinitionOutput, error)
+	deregisterJobDefinition func(*batch.DeregisterJobDefinitionInput) (*batch.DeregisterJobDefinitionOutput, error)
+	submitJob               func(*batch.SubmitJobInput) (*batch.SubmitJobOutput, error)
+	describeJobs            func(*batch.DescribeJobsInput) (*batch.DescribeJobsOutput, error)
+	listJobs                func(*batch.ListJobsInput) (*batch.ListJobsOutput, error)
+	describeJobQueues       func(*batch.DescribeJobQueuesInput) (*batch.DescribeJobQueuesOutput, error)
+}
+
+func (f *fakeBatchClient) DescribeJobDefinitions(_ context.Context, in *batch.DescribeJobDefinitionsInput, _ ...func(*batch.Options)) (*batch.DescribeJobDefinitionsOutput, error) {
+	return f.describeJobDefinitions(in)
+}
+
+func (f *fakeBatchClient) RegisterJobDefinition(_ context.Context, in *batch.RegisterJobDefinitionInput, _ ...func(*batch.Options)) (*batch.RegisterJobDefinitionOutput, error) {
+	return f.registerJobDefinition(in)
+}
+
+func (f *fakeBatchClient) DeregisterJobDefinition(_ context.Context, in *batch.DeregisterJobDefinitionInput, _ ...func(*batch.Options)) (*batch.DeregisterJobDefinitionOutput, error) {
+	return f.deregisterJobDefinition(in)
+}
+
+func (f *fakeBatchClient) SubmitJob(_ context.Context, in *batch.SubmitJobInput, _ ...func(*batch.Options)) (*batch.SubmitJobOutput, error) {
+	return f.submitJob(in)
+}
+
+func (f *fakeBatchClient) DescribeJobs(_ context.Context, in *batch.DescribeJobsInput, _ ...func(*batch.Options)) (*batch.DescribeJobsOutput, error) {
+	return f.describeJobs(in)
+}
+
+func (f *fakeBatchClient) ListJobs(_ context.Context, in *batch.ListJobsInput, _ ...func(*batch.Options)) (*batch.ListJobsOutput, error) {
+	return f.listJobs(in)
+}
+
+func (f *fakeBatchClient) DescribeJobQueues(_ context.Context, in *batch.DescribeJobQueuesInput, _ ...func(*batch.Options)) (*batch.DescribeJobQueuesOutput, error) {
+	return f.describeJobQueues(in)
+}
+
+func TestEachRegion_PartialFailure(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "multi-job", "type": "container"}`)
+	app.config.Regions = []RegionConfig{{Region: "us-east-1"}, {Region: "us-west-2"}}
+
+	var called []string
+	app.batchClients = map[string]batchAPI{
+		"us-east-1": &fakeBatchClient{
+			describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+				called = append(called, "us-east-1")
+				return nil, fmt.Errorf("access denied")
+			},
+		},
+		"us-west-2": &fakeBatchClient{
+			describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+				called = append(called, "us-west-2")
+				return &batch.DescribeJobDefinitionsOutput{
+					JobDefinitions: []batchTypes.JobDefinition{
+						{JobDefinitionName: aws.String("multi-job"), Revision: aws.Int32(2)},
+					},
+				}, nil
+			},
+		},
+	}
+
+	err := app.Status(context.Background(), StatusOption{})
+	if err == nil || !strings.Contains(err.Error(), "us-east-1: ") {
+		t.Errorf("expected aggregated error for us-east-1, got: %v", err)
+	}
+	if strings.Contains(err.Error(), "us-west-2") {
+		t.Errorf("us-west-2 should have succeeded, got: %v", err)
+	}
+	if len(called) != 2 {
+		t.Errorf("expected both regions to be queried, got: %v", called)
+	}
+}
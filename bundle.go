@@ -0,0 +1,114 @@
+package batcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Bundle is a self-contained, pre-rendered deploy artifact: the effective
+// config and the rendered job definition, so the same definition can be
+// promoted between environments without re-rendering. Hooks and credentials
+// belong to the environment that registers the bundle and are not included.
+type Bundle struct {
+	Version    string         `json:"version"`
+	Config     Config         `json:"config"`
+	Definition map[string]any `json:"definition"`
+}
+
+// BundleOption holds options for the bundle command.
+type BundleOption struct {
+	// Output is the file to write. Empty writes to stdout.
+	Output string
+}
+
+// Bundle renders the job definition and writes it with the effective config
+// as a single JSON document.
+func (app *App) Bundle(ctx context.Context, opt BundleOption) error {
+	rendered, err := app.render(ctx)
+	if err != nil {
+		return err
+	}
+	b, err := json.MarshalIndent(Bundle{
+		Version:    Version,
+		Config:     bundleConfig(*app.config),
+		Definition: rendered,
+	}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal bundle: %w", err)
+	}
+	b = append(b, '\n')
+	if opt.Output == "" {
+		_, err = os.Stdout.Write(b)
+		return err
+	}
+	if err := os.WriteFile(opt.Output, b, 0o644); err != nil {
+		return fmt.Errorf("failed to write bundle: %w", err)
+	}
+	fmt.Printf("Bundled %s\n", opt.Output)
+	return nil
+}
+
+// bundleConfig returns cfg without the settings a bundle does not carry:
+// hooks, credentials and the SSM parameters already resolved into region
+// and job_queue.
+func bundleConfig(cfg Config) Config {
+	cfg.RegionFromSSM = ""
+	cfg.JobQueueFromSSM = ""
+	cfg.Hooks = HooksConfig{}
+	cfg.RoleARN = ""
+	cfg.WebIdentityTokenFile = ""
+	cfg.Profile = ""
+	regions := make([]RegionConfig, 0, len(cfg.Regions))
+	for _, rc := range cfg.Regions {
+		regions = append(regions, RegionConfig{Region: rc.Region})
+	}
+	if len(regions) == 0 {
+		regions = nil
+	}
+	cfg.Regions = regions
+	return cfg
+}
+
+// NewFromBundle creates an App from a bundle written by the bundle command.
+// Its definition is used as is instead of rendering the template, and AWS
+// credentials come from the environment.
+func NewFromBundle(path string) (*App, error) {
+	bundle, err := ReadBundle(path)
+	if err != nil {
+		return nil, err
+	}
+	cfg := bundleConfig(bundle.Config)
+	if err := cfg.validate(); err != nil {
+		return nil, fmt.Errorf("invalid config in bundle %s: %w", path, err)
+	}
+	return &App{config: &cfg, configPath: path, definition: bundle.Definition}, nil
+}
+
+// UseBundle makes the app use the definition of bundle instead of rendering
+// its template. The target environment, credentials and hooks stay those of
+// the app's config.
+func (app *App) UseBundle(bundle *Bundle) {
+	app.definition = cloneJSON(bundle.Definition).(map[string]any)
+}
+
+// ReadBundle reads a bundle file written by the bundle command. It warns
+// when the bundle was created by another batcha version.
+func ReadBundle(path string) (*Bundle, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read bundle: %w", err)
+	}
+	var bundle Bundle
+	if err := json.Unmarshal(b, &bundle); err != nil {
+		return nil, fmt.Errorf("failed to parse bundle: %w", err)
+	}
+	if len(bundle.Definition) == 0 {
+		return nil, fmt.Errorf("bundle %s has no definition", path)
+	}
+	if bundle.Version != Version {
+		fmt.Fprintf(os.Stderr, "WARN: bundle %s was created by batcha %s (running %s)\n", path, bundle.Version, Version)
+	}
+	return &bundle, nil
+}
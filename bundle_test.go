@@ -0,0 +1,159 @@
+package batcha
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBundle_RoundTripDryRun(t *testing.T) {
+	t.Setenv("TEST_JOB_NAME", "my-job")
+	t.Setenv("TEST_IMAGE", "myrepo/myimage:v1")
+
+	ctx := context.Background()
+	app, err := New(ctx, filepath.Join("testdata", "config.yml"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	want := captureStdout(t, func() {
+		err = app.Register(ctx, RegisterOption{DryRun: true})
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	captureStdout(t, func() {
+		err = app.Bundle(ctx, BundleOption{Output: path})
+	})
+	if err != nil {
+		t.Fatalf("Bundle failed: %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var bundle Bundle
+	if err := json.Unmarshal(b, &bundle); err != nil {
+		t.Fatalf("bundle is not valid JSON: %v", err)
+	}
+	if bundle.Version != Version || bundle.Config.Region != "ap-northeast-1" {
+		t.Errorf("bundle version/region = %q/%q", bundle.Version, bundle.Config.Region)
+	}
+
+	// Rendering must not happen again: a changed environment is ignored.
+	t.Setenv("TEST_IMAGE", "other/image:v2")
+	bundled, err := NewFromBundle(path)
+	if err != nil {
+		t.Fatalf("NewFromBundle failed: %v", err)
+	}
+	got := captureStdout(t, func() {
+		err = bundled.Register(ctx, RegisterOption{DryRun: true})
+	})
+	if err != nil {
+		t.Fatalf("Register from bundle failed: %v", err)
+	}
+	if got != want {
+		t.Errorf("dry-run from bundle differs:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestNewFromBundle_NoDefinition(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	if err := os.WriteFile(path, []byte(`{"version":"dev","config":{"job_definition":"x.json"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewFromBundle(path); err == nil {
+		t.Fatal("expected an error for a bundle without a definition")
+	}
+}
+
+func TestBundle_ExcludesHooksAndCredentials(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "job.json"), []byte(`{"jobDefinitionName": "bundle-job", "type": "container"}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := `region: us-east-1
+job_definition: job.json
+profile: dev
+role_arn: arn:aws:iam::123456789012:role/deploy
+web_identity_token_file: /var/run/token
+regions:
+  - us-east-1
+  - region: eu-west-1
+    profile: eu
+    assume_role_arn: arn:aws:iam::210987654321:role/deploy
+hooks:
+  pre_register: notify-slack
+`
+	if err := os.WriteFile(filepath.Join(dir, "batcha.yml"), []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+	app, err := New(ctx, filepath.Join(dir, "batcha.yml"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	path := filepath.Join(dir, "bundle.json")
+	captureStdout(t, func() {
+		err = app.Bundle(ctx, BundleOption{Output: path})
+	})
+	if err != nil {
+		t.Fatalf("Bundle failed: %v", err)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, leaked := range []string{"hooks", "notify-slack", "profile", "role_arn", "web_identity_token_file", "assume_role_arn"} {
+		if strings.Contains(string(b), leaked) {
+			t.Errorf("bundle must not contain %q:\n%s", leaked, b)
+		}
+	}
+	bundled, err := NewFromBundle(path)
+	if err != nil {
+		t.Fatalf("NewFromBundle failed: %v", err)
+	}
+	if got := bundled.config.Regions; len(got) != 2 || got[1].Region != "eu-west-1" {
+		t.Errorf("bundle regions = %v, want us-east-1 and eu-west-1", got)
+	}
+}
+
+func TestNewFromBundle_InvalidConfig(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bundle.json")
+	bundle := `{"version":"dev","config":{"region":"us-east-1","regions":[{"region":""}]},"definition":{"jobDefinitionName":"x"}}`
+	if err := os.WriteFile(path, []byte(bundle), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	var err error
+	captureStderr(t, func() {
+		_, err = NewFromBundle(path)
+	})
+	if err == nil || !strings.Contains(err.Error(), "regions[0]: region is required") {
+		t.Errorf("expected the bundle config to be validated, got: %v", err)
+	}
+}
+
+func TestRegister_ConfigWithBundle(t *testing.T) {
+	var registered bool
+	app, _ := hooksApp(t, "  pre_register: 'grep -q bundled-job'\n", &registered)
+	app.UseBundle(&Bundle{Definition: map[string]any{"jobDefinitionName": "bundled-job", "type": "container"}})
+
+	var err error
+	captureStdout(t, func() {
+		captureStderr(t, func() {
+			err = app.Register(context.Background(), RegisterOption{NoSkip: true})
+		})
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if !registered {
+		t.Error("expected the bundle's definition to be registered in the config's region")
+	}
+}
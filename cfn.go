@@ -0,0 +1,52 @@
+package batcha
+
+import (
+	"context"
+	"fmt"
+	"text/template"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+)
+
+// cfnAPI is the subset of the CloudFormation API used by the cfn plugin.
+type cfnAPI interface {
+	ListExports(ctx context.Context, params *cloudformation.ListExportsInput, optFns ...func(*cloudformation.Options)) (*cloudformation.ListExportsOutput, error)
+}
+
+// cfnFuncMap returns the cfn_export template function. The exports of the
+// region are listed on first use and cached for the lifetime of the FuncMap.
+func cfnFuncMap(ctx context.Context, client cfnAPI) template.FuncMap {
+	var exports map[string]string
+	return template.FuncMap{
+		"cfn_export": func(name string) (string, error) {
+			if exports == nil {
+				var err error
+				if exports, err = listCFNExports(ctx, client); err != nil {
+					return "", err
+				}
+			}
+			v, ok := exports[name]
+			if !ok {
+				return "", fmt.Errorf("CloudFormation export %q not found (exports are per account and region)", name)
+			}
+			return v, nil
+		},
+	}
+}
+
+// listCFNExports returns every CloudFormation export by name.
+func listCFNExports(ctx context.Context, client cfnAPI) (map[string]string, error) {
+	exports := map[string]string{}
+	paginator := cloudformation.NewListExportsPaginator(client, &cloudformation.ListExportsInput{})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list CloudFormation exports: %w", err)
+		}
+		for _, e := range out.Exports {
+			exports[aws.ToString(e.Name)] = aws.ToString(e.Value)
+		}
+	}
+	return exports, nil
+}
@@ -0,0 +1,63 @@
+package batcha
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudformation"
+	cfnTypes "github.com/aws/aws-sdk-go-v2/service/cloudformation/types"
+	goconfig "github.com/kayac/go-config"
+)
+
+type fakeCFNClient struct {
+	// pages of exports, returned in order.
+	pages [][]cfnTypes.Export
+	calls int
+}
+
+func (c *fakeCFNClient) ListExports(_ context.Context, in *cloudformation.ListExportsInput, _ ...func(*cloudformation.Options)) (*cloudformation.ListExportsOutput, error) {
+	c.calls++
+	i := 0
+	if in.NextToken != nil {
+		i = len(aws.ToString(in.NextToken))
+	}
+	out := &cloudformation.ListExportsOutput{Exports: c.pages[i]}
+	if i+1 < len(c.pages) {
+		out.NextToken = aws.String(strings.Repeat("x", i+1))
+	}
+	return out, nil
+}
+
+func TestCFNFuncMap(t *testing.T) {
+	client := &fakeCFNClient{pages: [][]cfnTypes.Export{
+		{{Name: aws.String("network-JobQueue"), Value: aws.String("main-queue")}},
+		{{Name: aws.String("iam-ExecRoleArn"), Value: aws.String("arn:aws:iam::123456789012:role/exec")}},
+	}}
+	loader := goconfig.New()
+	loader.Funcs(cfnFuncMap(context.Background(), client))
+
+	src := `{"queue": "{{ cfn_export "network-JobQueue" }}", "role": "{{ cfn_export "iam-ExecRoleArn" }}"}`
+	var got map[string]any
+	if err := loader.LoadWithEnvJSONBytes(&got, []byte(src)); err != nil {
+		t.Fatal(err)
+	}
+	if got["queue"] != "main-queue" || got["role"] != "arn:aws:iam::123456789012:role/exec" {
+		t.Errorf("unexpected values: %v", got)
+	}
+	if client.calls != 2 {
+		t.Errorf("ListExports called %d times, want 2 (one listing of two pages)", client.calls)
+	}
+}
+
+func TestCFNFuncMap_NotFound(t *testing.T) {
+	loader := goconfig.New()
+	loader.Funcs(cfnFuncMap(context.Background(), &fakeCFNClient{pages: [][]cfnTypes.Export{nil}}))
+
+	var got map[string]any
+	err := loader.LoadWithEnvJSONBytes(&got, []byte(`{"queue": "{{ cfn_export "missing" }}"}`))
+	if err == nil || !strings.Contains(err.Error(), `CloudFormation export "missing" not found`) {
+		t.Errorf("expected a not found error, got: %v", err)
+	}
+}
@@ -2,6 +2,7 @@ package batcha
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"os"
 	"os/signal"
@@ -14,23 +15,58 @@ import (
 
 // CLI builds and returns the root cobra command.
 func CLI() *cobra.Command {
+	root, _ := newCLI()
+	return root
+}
+
+// newCLI builds the root command and a function that releases the --timeout
+// context, to be called once the command has run.
+func newCLI() (*cobra.Command, func()) {
+	var (
+		timeout   time.Duration
+		checkRefs bool
+		cancel    context.CancelFunc
+	)
 	root := &cobra.Command{
 		Use:   "batcha",
 		Short: "Declarative AWS Batch Job Definition deployment tool",
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if timeout > 0 {
+				var ctx context.Context
+				ctx, cancel = context.WithTimeout(cmd.Context(), timeout)
+				cmd.SetContext(ctx)
+			}
+			if checkRefs {
+				cmd.SetContext(withCheckRefs(cmd.Context()))
+			}
+			return nil
+		},
 	}
+	root.PersistentFlags().DurationVar(&timeout, "timeout", 0, "Abort the command after this duration (e.g. 30m, 2h; 0 means no limit)")
+	root.PersistentFlags().BoolVar(&checkRefs, "check-refs", false, "Fail rendering on Ref:: placeholders without a parameter default and undefined {{ }} fields")
 
 	root.AddCommand(
 		initCmd(),
 		registerCmd(),
+		bundleCmd(),
 		renderCmd(),
 		diffCmd(),
+		planCmd(),
+		diffRevisionsCmd(),
+		fingerprintCmd(),
+		deregisterCmd(),
+		copyCmd(),
 		statusCmd(),
 		runCmd(),
 		logsCmd(),
 		verifyCmd(),
 		versionCmd(),
 	)
-	return root
+	return root, func() {
+		if cancel != nil {
+			cancel()
+		}
+	}
 }
 
 func initCmd() *cobra.Command {
@@ -38,6 +74,8 @@ func initCmd() *cobra.Command {
 		jobDefName string
 		region     string
 		outputDir  string
+		exclude    []string
+		format     string
 	)
 	cmd := &cobra.Command{
 		Use:   "init",
@@ -47,51 +85,148 @@ func initCmd() *cobra.Command {
 				JobDefinitionName: jobDefName,
 				Region:            region,
 				OutputDir:         outputDir,
+				Exclude:           exclude,
+				Format:            format,
 			})
 		},
 	}
 	cmd.Flags().StringVar(&jobDefName, "job-definition-name", "", "Name of the AWS Batch job definition to fetch")
 	cmd.Flags().StringVar(&region, "region", "", "AWS region (falls back to AWS_REGION)")
 	cmd.Flags().StringVar(&outputDir, "output", ".", "Output directory for generated files")
+	cmd.Flags().StringSliceVar(&exclude, "exclude", nil, "Additional fields to strip (top-level keys or dotted paths, comma-separated)")
+	cmd.Flags().StringVar(&format, "format", "text", "Summary format (text, json: the generated paths, region and source revision)")
 	_ = cmd.MarkFlagRequired("job-definition-name")
 	return cmd
 }
 
 func registerCmd() *cobra.Command {
 	var (
-		configPath string
-		dryRun     bool
+		configPaths       []string
+		fromBundle        string
+		fromRendered      string
+		dryRun            bool
+		checkLimits       bool
+		revisionThreshold int
+		explain           bool
+		noSkip            bool
+		debugGoStruct     bool
+		validate          bool
+		concurrency       int
+		noConvert         bool
+		fingerprintTag    bool
 	)
 	cmd := &cobra.Command{
 		Use:   "register",
 		Short: "Register an AWS Batch Job Definition",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			opt := RegisterOption{
+				DryRun:            dryRun,
+				Validate:          validate,
+				Explain:           explain,
+				NoSkip:            noSkip,
+				DebugGoStruct:     debugGoStruct,
+				CheckLimits:       checkLimits,
+				RevisionThreshold: revisionThreshold,
+				FromRendered:      fromRendered,
+				Concurrency:       concurrency,
+				NoConvert:         noConvert,
+				FingerprintTag:    fingerprintTag,
+			}
+			var bundle *Bundle
+			if fromBundle != "" && len(configPaths) > 0 {
+				b, err := ReadBundle(fromBundle)
+				if err != nil {
+					return err
+				}
+				bundle = b
+			}
+			if len(configPaths) > 1 {
+				apps := make([]*App, 0, len(configPaths))
+				for _, path := range configPaths {
+					app, err := New(ctx, path)
+					if err != nil {
+						return fmt.Errorf("%s: %w", path, err)
+					}
+					if bundle != nil {
+						app.UseBundle(bundle)
+					}
+					apps = append(apps, app)
+				}
+				return RegisterAll(ctx, apps, opt)
+			}
+			var (
+				app *App
+				err error
+			)
+			if len(configPaths) == 0 {
+				app, err = NewFromBundle(fromBundle)
+			} else {
+				app, err = New(ctx, configPaths[0])
+			}
+			if err != nil {
+				return err
+			}
+			if bundle != nil {
+				app.UseBundle(bundle)
+			}
+			return app.Register(ctx, opt)
+		},
+	}
+	cmd.Flags().StringArrayVar(&configPaths, "config", nil, "Path to config YAML file (repeatable)")
+	cmd.Flags().IntVar(&concurrency, "concurrency", defaultRegisterConcurrency, "Number of configs registered at once with several --config")
+	cmd.Flags().BoolVar(&noConvert, "no-convert", false, "Send the template's keys as they are (the template must use PascalCase keys)")
+	cmd.Flags().BoolVar(&fingerprintTag, "fingerprint-tag", false, "Tag revisions with batcha/fingerprint and skip when an active revision has the current fingerprint")
+	cmd.Flags().StringVar(&fromBundle, "from-bundle", "", "Register the definition from a bundle file instead of rendering the template (with --config, only the definition is taken from it)")
+	cmd.Flags().StringVar(&fromRendered, "from-rendered", "", "Register an already-rendered JSON definition (from batcha render) instead of rendering the template")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Render template and print JSON without registering")
+	cmd.Flags().BoolVar(&validate, "validate", false, "With --dry-run, also run the verify checks and fail on errors")
+	cmd.Flags().BoolVar(&explain, "explain", false, "Explain why registration is skipped or performed (prints the triggering diff)")
+	cmd.Flags().BoolVar(&noSkip, "no-skip", false, "Always register a new revision without comparing against the remote definition")
+	cmd.Flags().BoolVar(&checkLimits, "check-limits", false, "Warn when the account is nearing the ACTIVE revision threshold before registering")
+	cmd.Flags().IntVar(&revisionThreshold, "revision-threshold", defaultRevisionThreshold, "ACTIVE revision count at which --check-limits warns")
+	cmd.Flags().BoolVar(&debugGoStruct, "debug-go-struct", false, "Print the unmarshaled RegisterJobDefinitionInput")
+	_ = cmd.Flags().MarkHidden("debug-go-struct")
+	cmd.MarkFlagsOneRequired("config", "from-bundle")
+	cmd.MarkFlagsMutuallyExclusive("from-rendered", "from-bundle")
+	return cmd
+}
+
+func bundleCmd() *cobra.Command {
+	var (
+		configPath string
+		output     string
+	)
+	cmd := &cobra.Command{
+		Use:   "bundle",
+		Short: "Write the effective config and rendered job definition as a single JSON file",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			app, err := New(ctx, configPath)
 			if err != nil {
 				return err
 			}
-			return app.Register(ctx, RegisterOption{DryRun: dryRun})
+			return app.Bundle(ctx, BundleOption{Output: output})
 		},
 	}
 	cmd.Flags().StringVar(&configPath, "config", "", "Path to config YAML file")
-	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Render template and print JSON without registering")
+	cmd.Flags().StringVar(&output, "output", "", "Bundle file to write (default: stdout)")
 	_ = cmd.MarkFlagRequired("config")
 	return cmd
 }
 
-func renderCmd() *cobra.Command {
+func fingerprintCmd() *cobra.Command {
 	var configPath string
 	cmd := &cobra.Command{
-		Use:   "render",
-		Short: "Render and print the job definition template",
+		Use:   "fingerprint",
+		Short: "Print a stable SHA-256 of the rendered job definition",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
 			app, err := New(ctx, configPath)
 			if err != nil {
 				return err
 			}
-			return app.Render(ctx)
+			return app.Fingerprint(ctx)
 		},
 	}
 	cmd.Flags().StringVar(&configPath, "config", "", "Path to config YAML file")
@@ -99,8 +234,63 @@ func renderCmd() *cobra.Command {
 	return cmd
 }
 
+func renderCmd() *cobra.Command {
+	var (
+		configPaths []string
+		output      string
+		awsCLI      bool
+		outputDir   string
+		raw         bool
+		noConvert   bool
+	)
+	cmd := &cobra.Command{
+		Use:   "render",
+		Short: "Render and print the job definition template",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			opt := RenderOption{Output: output, AWSCLI: awsCLI, Raw: raw, NoConvert: noConvert}
+			if outputDir != "" {
+				return RenderToDir(ctx, configPaths, outputDir, opt)
+			}
+			if len(configPaths) > 1 {
+				return fmt.Errorf("rendering several --config files requires --output-dir")
+			}
+			app, err := New(ctx, configPaths[0])
+			if err != nil {
+				return err
+			}
+			return app.Render(ctx, opt)
+		},
+	}
+	cmd.Flags().StringArrayVar(&configPaths, "config", nil, "Path to config YAML file (repeatable with --output-dir)")
+	cmd.Flags().StringVar(&output, "output", "json", "Output format (json, yaml)")
+	cmd.Flags().BoolVar(&awsCLI, "aws-cli", false, "Print JSON for aws batch register-job-definition --cli-input-json")
+	cmd.Flags().StringVar(&outputDir, "output-dir", "", "Write each rendered definition to <dir>/<jobDefinitionName>.json instead of stdout")
+	cmd.MarkFlagsMutuallyExclusive("output", "aws-cli")
+	cmd.Flags().BoolVar(&raw, "raw", false, "Print the template as rendered (camelCase), before conversion to the AWS API field names")
+	cmd.MarkFlagsMutuallyExclusive("output-dir", "aws-cli")
+	cmd.MarkFlagsMutuallyExclusive("raw", "aws-cli")
+	cmd.MarkFlagsMutuallyExclusive("raw", "output-dir")
+	cmd.Flags().BoolVar(&noConvert, "no-convert", false, "Print the template's keys as they are (the template must use PascalCase keys)")
+	cmd.MarkFlagsMutuallyExclusive("no-convert", "aws-cli")
+	cmd.MarkFlagsMutuallyExclusive("no-convert", "raw")
+	_ = cmd.MarkFlagRequired("config")
+	return cmd
+}
+
 func diffCmd() *cobra.Command {
-	var configPath string
+	var (
+		configPath string
+		labelA     string
+		labelB     string
+		compact    bool
+		lineNums   bool
+		algorithm  string
+		printRmt   bool
+		format     string
+		prune      bool
+		path       string
+	)
 	cmd := &cobra.Command{
 		Use:   "diff",
 		Short: "Show differences between local and remote job definition",
@@ -110,40 +300,238 @@ func diffCmd() *cobra.Command {
 			if err != nil {
 				return err
 			}
-			return app.Diff(ctx)
+			return app.Diff(ctx, DiffOption{
+				LabelA:        labelA,
+				LabelB:        labelB,
+				Compact:       compact,
+				LineNumbers:   lineNums,
+				Algorithm:     algorithm,
+				PrintRemote:   printRmt,
+				Format:        format,
+				PruneDefaults: prune,
+				Path:          path,
+			})
 		},
 	}
 	cmd.Flags().StringVar(&configPath, "config", "", "Path to config YAML file")
+	cmd.Flags().StringVar(&labelA, "label-a", "remote", "Label of the remote side in the diff header (---)")
+	cmd.Flags().StringVar(&labelB, "label-b", "local", "Label of the local side in the diff header (+++)")
+	cmd.Flags().BoolVar(&compact, "compact-diff", false, "Print only the changed field paths")
+	cmd.Flags().BoolVar(&lineNums, "line-numbers", false, "Prefix diff lines with remote and local line numbers")
+	cmd.Flags().StringVar(&algorithm, "algorithm", diffAlgorithmLCS, "Line diff algorithm (lcs, patience)")
+	cmd.Flags().BoolVar(&printRmt, "print-remote", false, "Print the normalized remote definition that diff compares against, without diffing")
+	cmd.Flags().StringVar(&format, "format", "text", "Output format (text, json: an array of change records)")
+	cmd.Flags().StringVar(&path, "path", "", "Diff only this dotted subtree of both definitions (e.g. containerProperties.environment)")
+	cmd.Flags().BoolVar(&prune, "prune-defaults", false, "Drop server-injected defaults the template does not set (e.g. privileged: false, empty arrays) from the remote before diffing")
+	cmd.MarkFlagsMutuallyExclusive("print-remote", "compact-diff")
+	cmd.MarkFlagsMutuallyExclusive("format", "compact-diff")
+	cmd.MarkFlagsMutuallyExclusive("format", "print-remote")
+	_ = cmd.MarkFlagRequired("config")
+	return cmd
+}
+
+func planCmd() *cobra.Command {
+	var (
+		configPaths    []string
+		all            bool
+		fingerprintTag bool
+	)
+	cmd := &cobra.Command{
+		Use:   "plan",
+		Short: "Show whether register would create, update or leave each job definition unchanged",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			apps := make([]*App, 0, len(configPaths))
+			for _, path := range configPaths {
+				app, err := New(ctx, path)
+				if err != nil {
+					return fmt.Errorf("%s: %w", path, err)
+				}
+				apps = append(apps, app)
+			}
+			return Plan(ctx, apps, PlanOption{All: all, FingerprintTag: fingerprintTag})
+		},
+	}
+	cmd.Flags().StringArrayVar(&configPaths, "config", nil, "Path to config YAML file (repeatable, one per environment)")
+	cmd.Flags().BoolVar(&all, "all", false, "Plan every region in each config's regions instead of only the default region")
+	cmd.Flags().BoolVar(&fingerprintTag, "fingerprint-tag", false, "Predict register --fingerprint-tag (a latest revision with the current fingerprint is unchanged)")
 	_ = cmd.MarkFlagRequired("config")
 	return cmd
 }
 
+func diffRevisionsCmd() *cobra.Command {
+	var (
+		configPath string
+		from       int32
+		to         int32
+		noRender   bool
+		name       string
+	)
+	cmd := &cobra.Command{
+		Use:   "diff-revisions",
+		Short: "Show differences between two registered revisions of the job definition",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := noRenderName(noRender, name)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			app, err := New(ctx, configPath)
+			if err != nil {
+				return err
+			}
+			return app.DiffRevisions(ctx, DiffRevisionsOption{From: from, To: to, Name: name})
+		},
+	}
+	cmd.Flags().StringVar(&configPath, "config", "", "Path to config YAML file")
+	cmd.Flags().Int32Var(&from, "from", 0, "Revision to compare from")
+	cmd.Flags().Int32Var(&to, "to", 0, "Revision to compare to")
+	addNoRenderFlags(cmd, &noRender, &name)
+	_ = cmd.MarkFlagRequired("config")
+	_ = cmd.MarkFlagRequired("from")
+	_ = cmd.MarkFlagRequired("to")
+	return cmd
+}
+
+func deregisterCmd() *cobra.Command {
+	var (
+		configPath string
+		revisions  []int32
+		dryRun     bool
+	)
+	cmd := &cobra.Command{
+		Use:   "deregister",
+		Short: "Deregister specific revisions of the job definition",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx := cmd.Context()
+			app, err := New(ctx, configPath)
+			if err != nil {
+				return err
+			}
+			return app.Deregister(ctx, DeregisterOption{Revisions: revisions, DryRun: dryRun})
+		},
+	}
+	cmd.Flags().StringVar(&configPath, "config", "", "Path to config YAML file")
+	cmd.Flags().Int32SliceVar(&revisions, "revision", nil, "Revision numbers to deregister (comma-separated or repeatable)")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the revisions that would be deregistered")
+	_ = cmd.MarkFlagRequired("config")
+	_ = cmd.MarkFlagRequired("revision")
+	return cmd
+}
+
+func copyCmd() *cobra.Command {
+	var (
+		configPath string
+		to         string
+		dryRun     bool
+		noRender   bool
+		name       string
+	)
+	cmd := &cobra.Command{
+		Use:   "copy",
+		Short: "Copy the latest job definition revision to another region",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := noRenderName(noRender, name)
+			if err != nil {
+				return err
+			}
+			ctx := cmd.Context()
+			app, err := New(ctx, configPath)
+			if err != nil {
+				return err
+			}
+			return app.Copy(ctx, CopyOption{To: to, Name: name, DryRun: dryRun})
+		},
+	}
+	cmd.Flags().StringVar(&configPath, "config", "", "Path to config YAML file")
+	cmd.Flags().StringVar(&to, "to", "", "Region to copy the job definition to")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Print the differences from the target's latest revision without registering")
+	addNoRenderFlags(cmd, &noRender, &name)
+	_ = cmd.MarkFlagRequired("config")
+	_ = cmd.MarkFlagRequired("to")
+	return cmd
+}
+
 func statusCmd() *cobra.Command {
-	var configPath string
+	var (
+		configPath string
+		output     string
+		tmpl       string
+		noRender   bool
+		name       string
+	)
 	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show the current status of the job definition on AWS",
 		RunE: func(cmd *cobra.Command, args []string) error {
+			name, err := noRenderName(noRender, name)
+			if err != nil {
+				return err
+			}
 			ctx := cmd.Context()
 			app, err := New(ctx, configPath)
 			if err != nil {
 				return err
 			}
-			return app.Status(ctx)
+			return app.Status(ctx, StatusOption{Output: output, Template: tmpl, Name: name})
 		},
 	}
 	cmd.Flags().StringVar(&configPath, "config", "", "Path to config YAML file")
+	cmd.Flags().StringVar(&output, "output", "text", "Output format (text, wide, json, env: shell variable assignments)")
+	cmd.Flags().StringVar(&tmpl, "template", "", "Go template to format the status (e.g. '{{.Revision}} {{.Image}}')")
+	cmd.MarkFlagsMutuallyExclusive("output", "template")
+	addNoRenderFlags(cmd, &noRender, &name)
 	_ = cmd.MarkFlagRequired("config")
 	return cmd
 }
 
+// addNoRenderFlags adds --no-render and --name to commands that only inspect
+// the remote definition, so a broken template does not block them.
+func addNoRenderFlags(cmd *cobra.Command, noRender *bool, name *string) {
+	cmd.Flags().BoolVar(noRender, "no-render", false, "Do not render the template; inspect the job definition given by --name")
+	cmd.Flags().StringVar(name, "name", "", "Job definition name to inspect (with --no-render)")
+	cmd.MarkFlagsRequiredTogether("no-render", "name")
+}
+
+// noRenderName returns the job definition name to inspect with --no-render,
+// or "" when the template is to be rendered. Cobra only checks that both
+// flags are given, so --no-render=false and an empty --name are rejected here.
+func noRenderName(noRender bool, name string) (string, error) {
+	if !noRender {
+		if name != "" {
+			return "", fmt.Errorf("--name requires --no-render")
+		}
+		return "", nil
+	}
+	if name == "" {
+		return "", fmt.Errorf("--no-render requires a job definition name in --name")
+	}
+	return name, nil
+}
+
 func runCmd() *cobra.Command {
 	var (
 		configPath string
 		jobQueue   string
+		autoQueue  bool
 		jobName    string
+		genName    bool
 		params     []string
 		wait       bool
+		fromJob    string
+		paramFile  string
+		output     string
+		pollLogs   bool
+		jobDefArn  string
+		cmdFile    string
+		shell      string
+		diff       bool
+		force      bool
+		statusFile string
+		concurrent int
+		timeoutSec int32
+		shareID    string
+		retryJob   string
 	)
 	cmd := &cobra.Command{
 		Use:   "run",
@@ -163,29 +551,78 @@ func runCmd() *cobra.Command {
 				paramMap[k] = v
 			}
 			return app.Run(ctx, RunOption{
-				JobQueue:   jobQueue,
-				JobName:    jobName,
-				Parameters: paramMap,
-				Wait:       wait,
+				JobQueue:     jobQueue,
+				AutoQueue:    autoQueue,
+				JobName:      jobName,
+				GenerateName: genName,
+				Parameters:   paramMap,
+				Wait:         wait,
+				PollLogs:     pollLogs,
+
+				ParameterFile:     paramFile,
+				ParametersFromJob: fromJob,
+				Output:            output,
+				JobDefinitionArn:  jobDefArn,
+				CommandFile:       cmdFile,
+				Shell:             shell,
+				Diff:              diff,
+				Force:             force,
+				FromStatusFile:    statusFile,
+				Concurrency:       concurrent,
+				TimeoutSeconds:    timeoutSec,
+				ShareIdentifier:   shareID,
+				RetryFailed:       retryJob,
 			})
 		},
 	}
 	cmd.Flags().StringVar(&configPath, "config", "", "Path to config YAML file")
 	cmd.Flags().StringVar(&jobQueue, "job-queue", "", "AWS Batch job queue name (overrides config)")
-	cmd.Flags().StringVar(&jobName, "job-name", "", "Job name (defaults to job definition name)")
+	cmd.Flags().BoolVar(&autoQueue, "auto-queue", false, "Without a configured job queue, use the queue tagged batcha/default=true")
+	cmd.Flags().StringVar(&jobName, "job-name", "", "Job name (defaults to default_job_name in config, then the job definition name)")
+	cmd.Flags().BoolVar(&genName, "generate-name", false, "Append a timestamp and random suffix to the job name (e.g. myjob-20240102-150405-ab12)")
 	cmd.Flags().StringArrayVar(&params, "parameter", nil, "Parameter overrides (key=value, repeatable)")
 	cmd.Flags().BoolVar(&wait, "wait", false, "Wait for the job to complete")
+	cmd.Flags().Int32Var(&timeoutSec, "timeout-seconds", 0, "Override the job definition's attempt timeout for this run (at least 60)")
+	cmd.Flags().StringVar(&shareID, "share-identifier", "", "Fair-share identifier, required by job queues with a scheduling policy")
+	cmd.Flags().BoolVar(&pollLogs, "poll-logs", false, "With --wait, print the newest log lines on every poll")
+	cmd.Flags().StringVar(&jobDefArn, "job-definition-arn", "", "Submit against this exact job definition ARN instead of the latest active revision")
+	cmd.Flags().StringVar(&cmdFile, "command-file", "", "Script file to run as the container command (via <shell> -c)")
+	cmd.Flags().StringVar(&shell, "shell", "sh", "Shell used to run --command-file")
+	cmd.Flags().StringVar(&paramFile, "parameter-file", "", "YAML or JSON file of parameters (--parameter flags win)")
+	cmd.Flags().StringVar(&output, "output", "text", "Output format (text, json)")
+	cmd.Flags().StringVar(&fromJob, "parameters-from-job", "", "Reuse the parameters of a previous job (--parameter flags win)")
+	cmd.Flags().BoolVar(&diff, "diff", false, "Compare the local template with the active definition first and abort if they differ")
+	cmd.Flags().BoolVar(&force, "force", false, "With --diff, print the differences but submit anyway")
+	cmd.Flags().StringVar(&statusFile, "from-status-file", "", "Submit one job per entry of this JSON status file and print the updated status file")
+	cmd.Flags().IntVar(&concurrent, "concurrency", defaultRunConcurrency, "Maximum number of submissions in flight with --from-status-file")
+	cmd.Flags().StringVar(&retryJob, "retry-failed", "", "Resubmit this failed job with the same definition revision, queue, parameters and overrides")
+	// --retry-failed takes everything from the failed job.
+	for _, name := range []string{
+		"job-queue", "auto-queue", "job-name", "generate-name", "parameter", "parameter-file", "parameters-from-job",
+		"job-definition-arn", "command-file", "diff", "from-status-file", "timeout-seconds", "share-identifier",
+	} {
+		cmd.MarkFlagsMutuallyExclusive("retry-failed", name)
+	}
 	_ = cmd.MarkFlagRequired("config")
 	return cmd
 }
 
 func logsCmd() *cobra.Command {
 	var (
-		configPath string
-		jobID      string
-		jobQueue   string
-		follow     bool
-		since      string
+		configPath  string
+		jobID       string
+		jobQueue    string
+		autoQueue   bool
+		follow      bool
+		watch       bool
+		since       string
+		allRunning  bool
+		concurrency int
+		output      string
+		maxEvents   int
+		sinceOK     bool
+		heartbeat   time.Duration
+		followTO    time.Duration
 	)
 	cmd := &cobra.Command{
 		Use:   "logs",
@@ -204,38 +641,83 @@ func logsCmd() *cobra.Command {
 				}
 			}
 			return app.Logs(ctx, LogsOption{
-				JobID:    jobID,
-				JobQueue: jobQueue,
-				Follow:   follow,
-				Since:    sinceDur,
+				JobID:       jobID,
+				JobQueue:    jobQueue,
+				AutoQueue:   autoQueue,
+				Follow:      follow,
+				Watch:       watch,
+				Since:       sinceDur,
+				AllRunning:  allRunning,
+				Concurrency: concurrency,
+				Output:      output,
+				MaxEvents:   maxEvents,
+
+				SinceLatestSuccess: sinceOK,
+				Heartbeat:          heartbeat,
+				FollowTimeout:      followTO,
 			})
 		},
 	}
 	cmd.Flags().StringVar(&configPath, "config", "", "Path to config YAML file")
 	cmd.Flags().StringVar(&jobID, "job-id", "", "AWS Batch job ID (if omitted, finds the latest job)")
 	cmd.Flags().StringVar(&jobQueue, "job-queue", "", "AWS Batch job queue name (overrides config)")
+	cmd.Flags().BoolVar(&autoQueue, "auto-queue", false, "Without a configured job queue, use the queue tagged batcha/default=true")
 	cmd.Flags().BoolVarP(&follow, "follow", "f", false, "Follow logs in real time")
+	cmd.Flags().BoolVar(&watch, "watch", false, "Follow the latest job, then keep following each newer job of the definition until interrupted")
 	cmd.Flags().StringVar(&since, "since", "", "Show logs since duration (e.g. 1h, 30m)")
+	cmd.Flags().BoolVar(&allRunning, "all-running", false, "Tail logs of all RUNNING jobs of the job definition")
+	cmd.Flags().IntVar(&concurrency, "concurrency", defaultLogsConcurrency, "Maximum number of log streams tailed at once with --all-running")
+	cmd.Flags().StringVar(&output, "output", "text", "Output format (text, json)")
+	cmd.Flags().IntVar(&maxEvents, "max-events", 0, "Stop after printing this many events in total (0 means unlimited)")
+	cmd.Flags().BoolVar(&sinceOK, "since-latest-success", false, "Show logs since the most recent successful job of the job definition finished")
+	cmd.Flags().DurationVar(&heartbeat, "heartbeat", 0, "With --follow, print a waiting line to stderr after this long without new events (e.g. 30s)")
+	cmd.Flags().DurationVar(&followTO, "follow-timeout", 0, "With --follow, stop after this long without new events even if the job is still running")
+	cmd.MarkFlagsMutuallyExclusive("all-running", "job-id")
+	cmd.MarkFlagsMutuallyExclusive("since-latest-success", "since")
+	cmd.MarkFlagsMutuallyExclusive("since-latest-success", "all-running")
+	cmd.MarkFlagsMutuallyExclusive("watch", "job-id")
+	cmd.MarkFlagsMutuallyExclusive("watch", "all-running")
+	cmd.MarkFlagsMutuallyExclusive("watch", "since-latest-success")
 	_ = cmd.MarkFlagRequired("config")
 	return cmd
 }
 
 func verifyCmd() *cobra.Command {
-	var configPath string
+	var (
+		configPath    string
+		debugGoStruct bool
+		output        string
+		dir           string
+		pattern       string
+		concurrency   int
+		reportFile    string
+	)
 	cmd := &cobra.Command{
 		Use:   "verify",
 		Short: "Validate the job definition template locally",
 		RunE: func(cmd *cobra.Command, args []string) error {
 			ctx := cmd.Context()
+			opt := VerifyOption{DebugGoStruct: debugGoStruct, Output: output, Pattern: pattern, Concurrency: concurrency, ReportFile: reportFile}
+			if dir != "" {
+				return VerifyDir(ctx, dir, opt)
+			}
 			app, err := New(ctx, configPath)
 			if err != nil {
 				return err
 			}
-			return app.Verify(ctx)
+			return app.Verify(ctx, opt)
 		},
 	}
 	cmd.Flags().StringVar(&configPath, "config", "", "Path to config YAML file")
-	_ = cmd.MarkFlagRequired("config")
+	cmd.Flags().StringVar(&output, "output", "text", "Output format (text, json)")
+	cmd.Flags().StringVar(&reportFile, "report", "", "Also write the JSON report to this file, even when verification fails")
+	cmd.Flags().StringVar(&dir, "dir", "", "Verify every config file under this directory")
+	cmd.Flags().StringVar(&pattern, "pattern", defaultVerifyPattern, "Base name glob of the config files found by --dir")
+	cmd.Flags().IntVar(&concurrency, "concurrency", defaultVerifyConcurrency, "Number of configs verified at once with --dir")
+	cmd.Flags().BoolVar(&debugGoStruct, "debug-go-struct", false, "Print the unmarshaled RegisterJobDefinitionInput")
+	_ = cmd.Flags().MarkHidden("debug-go-struct")
+	cmd.MarkFlagsOneRequired("config", "dir")
+	cmd.MarkFlagsMutuallyExclusive("config", "dir")
 	return cmd
 }
 
@@ -251,10 +733,17 @@ func versionCmd() *cobra.Command {
 
 // Run executes the CLI with signal handling.
 func Run() int {
+	return run(os.Args[1:])
+}
+
+// run executes the CLI with args.
+func run(args []string) int {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
-	cmd := CLI()
+	cmd, cancel := newCLI()
+	defer cancel()
+	cmd.SetArgs(args)
 	cmd.SetContext(ctx)
 	cmd.SilenceUsage = true
 	cmd.SilenceErrors = true
@@ -263,8 +752,18 @@ func Run() int {
 		if _, ok := err.(*DiffError); ok {
 			return 1
 		}
+		// Only the global --timeout maps to its exit code; other deadlines
+		// (hooks, SDK calls) are ordinary failures.
+		timeout, _ := cmd.PersistentFlags().GetDuration("timeout")
+		if timeout > 0 && errors.Is(err, context.DeadlineExceeded) {
+			fmt.Fprintf(os.Stderr, "Error: timed out after %s: %s\n", timeout, err)
+			return exitCodeTimeout
+		}
 		fmt.Fprintf(os.Stderr, "Error: %s\n", err)
 		return 1
 	}
 	return 0
 }
+
+// exitCodeTimeout is returned when --timeout expires, matching timeout(1).
+const exitCodeTimeout = 124
@@ -0,0 +1,75 @@
+package batcha
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// hookConfig writes a config whose pre_register hook blocks for 5 seconds.
+func hookConfig(t *testing.T) string {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "job.json"), []byte(`{"jobDefinitionName": "dev", "type": "container"}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := "region: us-east-1\njob_definition: job.json\nhooks:\n  pre_register: 'exec sleep 5'\n"
+	if err := os.WriteFile(filepath.Join(dir, "batcha.yml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return filepath.Join(dir, "batcha.yml")
+}
+
+func TestRun_Timeout(t *testing.T) {
+	var code int
+	stderr := captureStderr(t, func() {
+		code = run([]string{"--timeout", "100ms", "register", "--config", hookConfig(t)})
+	})
+	if code != exitCodeTimeout {
+		t.Errorf("expected exit code %d, got %d (stderr: %s)", exitCodeTimeout, code, stderr)
+	}
+	if !strings.Contains(stderr, "Error: timed out after 100ms") {
+		t.Errorf("expected the timeout message, got: %s", stderr)
+	}
+}
+
+func TestRun_DeadlineWithoutTimeout(t *testing.T) {
+	orig := hookTimeout
+	hookTimeout = 50 * time.Millisecond
+	defer func() { hookTimeout = orig }()
+
+	var code int
+	stderr := captureStderr(t, func() {
+		code = run([]string{"register", "--config", hookConfig(t)})
+	})
+	if code != 1 {
+		t.Errorf("expected exit code 1 for a hook timeout, got %d (stderr: %s)", code, stderr)
+	}
+	if strings.Contains(stderr, "timed out after 0s") {
+		t.Errorf("a hook timeout must not be reported as --timeout: %s", stderr)
+	}
+}
+
+func TestRun_NoRenderRequiresName(t *testing.T) {
+	tests := []struct {
+		name string
+		args []string
+		want string
+	}{
+		{name: "no-render disabled", args: []string{"--no-render=false", "--name", "my-job"}, want: "--name requires --no-render"},
+		{name: "empty name", args: []string{"--no-render", "--name", ""}, want: "--no-render requires a job definition name"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var code int
+			stderr := captureStderr(t, func() {
+				code = run(append([]string{"status", "--config", hookConfig(t)}, tt.args...))
+			})
+			if code != 1 || !strings.Contains(stderr, tt.want) {
+				t.Errorf("expected exit code 1 and %q, got %d: %s", tt.want, code, stderr)
+			}
+		})
+	}
+}
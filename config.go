@@ -1,34 +1,146 @@
 package batcha
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"path/filepath"
 
 	"gopkg.in/yaml.v2"
 )
 
 // Config represents the batcha configuration file.
 type Config struct {
-	Region        string   `yaml:"region"`
-	JobDefinition string   `yaml:"job_definition"`
-	JobQueue      string   `yaml:"job_queue"`
-	Plugins       []Plugin `yaml:"plugins"`
+	Region        string   `yaml:"region" json:"region"`
+	JobDefinition string   `yaml:"job_definition" json:"job_definition"`
+	JobQueue      string   `yaml:"job_queue" json:"job_queue"`
+	Plugins       []Plugin `yaml:"plugins" json:"plugins"`
+
+	// DefaultJobName is the job name used by run when --job-name is not
+	// given, instead of the job definition name. It may use the env and
+	// must_env template functions.
+	DefaultJobName string `yaml:"default_job_name,omitempty" json:"default_job_name,omitempty"`
+
+	// DefaultParameters are submitted with every run unless overridden.
+	DefaultParameters map[string]string `yaml:"default_parameters,omitempty" json:"default_parameters,omitempty"`
+
+	// RegionFromSSM and JobQueueFromSSM name SSM parameters that hold the
+	// region and job queue. They are read in region (or AWS_REGION) and
+	// replace region and job_queue.
+	RegionFromSSM   string `yaml:"region_from_ssm,omitempty" json:"region_from_ssm,omitempty"`
+	JobQueueFromSSM string `yaml:"job_queue_from_ssm,omitempty" json:"job_queue_from_ssm,omitempty"`
+
+	// Regions fans register, diff and status out to several regions.
+	Regions []RegionConfig `yaml:"regions,omitempty" json:"regions,omitempty"`
+
+	Verify VerifyConfig `yaml:"verify,omitempty" json:"verify,omitempty"`
+
+	// Hooks are shell commands run around register.
+	Hooks HooksConfig `yaml:"hooks,omitempty" json:"hooks,omitzero"`
+
+	// RoleARN is assumed for all AWS calls. Combined with
+	// WebIdentityTokenFile it is assumed via web identity (e.g. GitHub Actions OIDC).
+	RoleARN              string `yaml:"role_arn,omitempty" json:"role_arn,omitempty"`
+	WebIdentityTokenFile string `yaml:"web_identity_token_file,omitempty" json:"web_identity_token_file,omitempty"`
+	// Profile is the AWS shared config profile used for all AWS calls.
+	Profile string `yaml:"profile,omitempty" json:"profile,omitempty"`
+
+	// active is the regions entry for Region, whose profile and
+	// assume_role_arn override Profile and RoleARN.
+	active RegionConfig
+}
+
+// RegionConfig is an entry of regions. It is written either as a plain
+// region name or as an object with per-region credentials, for regions that
+// live in other accounts.
+type RegionConfig struct {
+	Region        string `yaml:"region" json:"region"`
+	Profile       string `yaml:"profile,omitempty" json:"profile,omitempty"`
+	AssumeRoleARN string `yaml:"assume_role_arn,omitempty" json:"assume_role_arn,omitempty"`
+}
+
+// UnmarshalYAML accepts a plain region name as well as the object form.
+func (rc *RegionConfig) UnmarshalYAML(unmarshal func(any) error) error {
+	var region string
+	if err := unmarshal(&region); err == nil {
+		*rc = RegionConfig{Region: region}
+		return nil
+	}
+	type plain RegionConfig
+	return unmarshal((*plain)(rc))
+}
+
+// setRegion makes region the active region, applying the credentials of its
+// regions entry, if any.
+func (cfg *Config) setRegion(region string) {
+	cfg.Region = region
+	cfg.active = RegionConfig{}
+	for _, rc := range cfg.Regions {
+		if rc.Region == region {
+			cfg.active = rc
+			return
+		}
+	}
+}
+
+// profile returns the shared config profile for the active region.
+func (cfg *Config) profile() string {
+	if cfg.active.Profile != "" {
+		return cfg.active.Profile
+	}
+	return cfg.Profile
+}
+
+// roleARN returns the role to assume for the active region.
+func (cfg *Config) roleARN() string {
+	if cfg.active.AssumeRoleARN != "" {
+		return cfg.active.AssumeRoleARN
+	}
+	return cfg.RoleARN
+}
+
+// VerifyConfig holds policies enforced by the verify command.
+type VerifyConfig struct {
+	// AllowedImagePrefixes restricts container images to these registry
+	// prefixes. Empty allows any image.
+	AllowedImagePrefixes []string `yaml:"allowed_image_prefixes,omitempty" json:"allowed_image_prefixes,omitempty"`
+	// RequiredTags must be present in tags with non-empty values.
+	RequiredTags []string `yaml:"required_tags,omitempty" json:"required_tags,omitempty"`
+	// ForbidPrivileged rejects privileged containers and warns on containers
+	// that run as root.
+	ForbidPrivileged bool `yaml:"forbid_privileged,omitempty" json:"forbid_privileged,omitempty"`
+	// MinAttemptDurationSeconds is the attempt timeout below which verify
+	// warns on definitions that retry (default 300).
+	MinAttemptDurationSeconds int32 `yaml:"min_attempt_duration_seconds,omitempty" json:"min_attempt_duration_seconds,omitempty"`
 }
 
 // Plugin represents a plugin configuration block.
 type Plugin struct {
-	Name   string       `yaml:"name"`
-	Config PluginConfig `yaml:"config"`
+	Name   string       `yaml:"name" json:"name"`
+	Config PluginConfig `yaml:"config" json:"config"`
 }
 
 // PluginConfig holds plugin-specific settings.
 type PluginConfig struct {
-	URL string `yaml:"url"`
+	URL string `yaml:"url" json:"url"`
+	// Command is the program and leading arguments run by the exec plugin.
+	Command []string `yaml:"command,omitempty" json:"command,omitempty"`
+	// Timeout bounds each exec plugin run, as a Go duration (default 30s).
+	Timeout string `yaml:"timeout,omitempty" json:"timeout,omitempty"`
 }
 
-// LoadConfig reads and validates the YAML config file.
-func LoadConfig(path string) (*Config, error) {
-	b, err := os.ReadFile(path)
+// LoadConfig reads and validates the YAML config file. path may also be an
+// http(s):// or s3:// URL.
+func LoadConfig(ctx context.Context, path string) (*Config, error) {
+	var (
+		b   []byte
+		err error
+	)
+	if isRemotePath(path) {
+		b, err = fetchRemote(ctx, nil, path)
+	} else {
+		b, err = os.ReadFile(path)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to read config: %w", err)
 	}
@@ -39,12 +151,69 @@ func LoadConfig(path string) (*Config, error) {
 	if cfg.JobDefinition == "" {
 		return nil, fmt.Errorf("job_definition is required in config")
 	}
-	// Fallback to environment variables for region
+	// A relative job_definition cannot be resolved against a URL.
+	if isRemotePath(path) && !filepath.IsAbs(cfg.JobDefinition) && !isRemotePath(cfg.JobDefinition) {
+		return nil, fmt.Errorf("job_definition must be an absolute path or URL when the config is loaded from a URL, got %q", cfg.JobDefinition)
+	}
+	if err := cfg.validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// validate fills in the default region and checks the settings that do not
+// depend on where the config was read from.
+func (cfg *Config) validate() error {
+	// Fallback to the first of regions, then environment variables for region
+	if cfg.Region == "" && len(cfg.Regions) > 0 {
+		cfg.Region = cfg.Regions[0].Region
+	}
 	if cfg.Region == "" {
 		cfg.Region = os.Getenv("AWS_REGION")
 	}
 	if cfg.Region == "" {
 		cfg.Region = os.Getenv("AWS_DEFAULT_REGION")
 	}
-	return &cfg, nil
+	if cfg.WebIdentityTokenFile != "" && cfg.RoleARN == "" {
+		return fmt.Errorf("role_arn is required when web_identity_token_file is set")
+	}
+	if cfg.RegionFromSSM != "" && len(cfg.Regions) > 0 {
+		return fmt.Errorf("region_from_ssm cannot be combined with regions")
+	}
+	if (cfg.RegionFromSSM != "" || cfg.JobQueueFromSSM != "") && cfg.Region == "" {
+		return fmt.Errorf("region or AWS_REGION is required to read region_from_ssm/job_queue_from_ssm from SSM")
+	}
+	for i, rc := range cfg.Regions {
+		if rc.Region == "" {
+			return fmt.Errorf("regions[%d]: region is required", i)
+		}
+	}
+	cfg.setRegion(cfg.Region)
+	return nil
+}
+
+// usesSSM reports whether the config reads settings from SSM.
+func (cfg *Config) usesSSM() bool {
+	return cfg.RegionFromSSM != "" || cfg.JobQueueFromSSM != ""
+}
+
+// resolveFromSSM replaces region and job_queue with the values of
+// region_from_ssm and job_queue_from_ssm. client must target the bootstrap
+// region (cfg.Region before resolution).
+func resolveFromSSM(ctx context.Context, cfg *Config, client ssmAPI) error {
+	if cfg.JobQueueFromSSM != "" {
+		v, err := getSSMParameter(ctx, client, cfg.JobQueueFromSSM, true)
+		if err != nil {
+			return fmt.Errorf("job_queue_from_ssm: %w", err)
+		}
+		cfg.JobQueue = v
+	}
+	if cfg.RegionFromSSM != "" {
+		v, err := getSSMParameter(ctx, client, cfg.RegionFromSSM, true)
+		if err != nil {
+			return fmt.Errorf("region_from_ssm: %w", err)
+		}
+		cfg.setRegion(v)
+	}
+	return nil
 }
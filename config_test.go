@@ -1,13 +1,23 @@
 package batcha
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	ssmTypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
 )
 
 func TestLoadConfig(t *testing.T) {
-	cfg, err := LoadConfig(filepath.Join("testdata", "config.yml"))
+	cfg, err := LoadConfig(context.Background(), filepath.Join("testdata", "config.yml"))
 	if err != nil {
 		t.Fatalf("LoadConfig failed: %v", err)
 	}
@@ -30,7 +40,7 @@ func TestLoadConfig_RegionFallback(t *testing.T) {
 	}
 
 	t.Setenv("AWS_REGION", "us-west-2")
-	cfg, err := LoadConfig(cfgPath)
+	cfg, err := LoadConfig(context.Background(), cfgPath)
 	if err != nil {
 		t.Fatalf("LoadConfig failed: %v", err)
 	}
@@ -49,7 +59,7 @@ func TestLoadConfig_JobQueue(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	cfg, err := LoadConfig(cfgPath)
+	cfg, err := LoadConfig(context.Background(), cfgPath)
 	if err != nil {
 		t.Fatalf("LoadConfig failed: %v", err)
 	}
@@ -65,8 +75,193 @@ func TestLoadConfig_MissingJobDefinition(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	_, err := LoadConfig(cfgPath)
+	_, err := LoadConfig(context.Background(), cfgPath)
 	if err == nil {
 		t.Fatal("expected error for missing job_definition")
 	}
 }
+
+func TestLoadConfig_URL(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/batcha.yml", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, "region: us-west-2\njob_definition: "+r.URL.Query().Get("job")+"\n")
+	})
+	mux.HandleFunc("/job.json", func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"jobDefinitionName": "remote-job", "type": "container"}`)
+	})
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	app, err := New(context.Background(), srv.URL+"/batcha.yml?job="+srv.URL+"/job.json")
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if app.config.Region != "us-west-2" {
+		t.Errorf("Region = %q, want us-west-2", app.config.Region)
+	}
+	rendered, err := app.render(context.Background())
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if rendered["jobDefinitionName"] != "remote-job" {
+		t.Errorf("jobDefinitionName = %v, want remote-job", rendered["jobDefinitionName"])
+	}
+
+	if _, err := LoadConfig(context.Background(), srv.URL+"/batcha.yml?job=job.json"); err == nil || !strings.Contains(err.Error(), "absolute path or URL") {
+		t.Errorf("expected relative job_definition error, got: %v", err)
+	}
+	if _, err := LoadConfig(context.Background(), srv.URL+"/missing.yml"); err == nil || !strings.Contains(err.Error(), "404") {
+		t.Errorf("expected 404 error, got: %v", err)
+	}
+}
+
+func TestLoadConfig_URLCanceled(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-r.Context().Done() // hang until the client gives up
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := LoadConfig(ctx, srv.URL+"/batcha.yml")
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected the caller's deadline to stop the fetch, got: %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > 5*time.Second {
+		t.Errorf("fetch took %s after the deadline", elapsed)
+	}
+}
+
+func TestRemoteTemplate_S3UsesConfigCredentials(t *testing.T) {
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(t.TempDir(), "config"))
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", filepath.Join(t.TempDir(), "credentials"))
+	t.Setenv("AWS_PROFILE", "")
+
+	app := &App{config: &Config{Region: "us-east-1", Profile: "batcha-test-missing", JobDefinition: "s3://bucket/job.json"}}
+	_, err := app.render(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "batcha-test-missing") {
+		t.Errorf("expected the template fetch to use the config's profile, got: %v", err)
+	}
+}
+
+func TestResolveFromSSM(t *testing.T) {
+	client := &fakeSSMClient{params: map[string]ssmTypes.Parameter{
+		"/env/region":    {Type: ssmTypes.ParameterTypeString, Value: aws.String("eu-west-1")},
+		"/env/job-queue": {Type: ssmTypes.ParameterTypeString, Value: aws.String("prod-queue")},
+	}}
+	cfg := &Config{Region: "us-east-1", JobQueue: "default", RegionFromSSM: "/env/region", JobQueueFromSSM: "/env/job-queue"}
+	if err := resolveFromSSM(context.Background(), cfg, client); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Region != "eu-west-1" || cfg.JobQueue != "prod-queue" {
+		t.Errorf("region = %q, job queue = %q", cfg.Region, cfg.JobQueue)
+	}
+
+	cfg = &Config{Region: "us-east-1", RegionFromSSM: "/env/missing"}
+	if err := resolveFromSSM(context.Background(), cfg, client); err == nil || !strings.Contains(err.Error(), "region_from_ssm") {
+		t.Errorf("expected region_from_ssm error, got %v", err)
+	}
+}
+
+func TestLoadConfig_FromSSMNeedsRegion(t *testing.T) {
+	t.Setenv("AWS_REGION", "")
+	t.Setenv("AWS_DEFAULT_REGION", "")
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yml")
+	if err := os.WriteFile(cfgPath, []byte("job_definition: job.json\nregion_from_ssm: /env/region\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	_, err := LoadConfig(context.Background(), cfgPath)
+	if err == nil || !strings.Contains(err.Error(), "region or AWS_REGION is required") {
+		t.Errorf("expected bootstrap region error, got %v", err)
+	}
+}
+
+func TestLoadConfig_RegionsWithCredentials(t *testing.T) {
+	dir := t.TempDir()
+	cfgPath := filepath.Join(dir, "config.yml")
+	cfgYAML := `job_definition: job.json
+role_arn: arn:aws:iam::111111111111:role/deploy
+regions:
+  - us-east-1
+  - region: eu-west-1
+    profile: eu-account
+  - region: ap-northeast-1
+    assume_role_arn: arn:aws:iam::333333333333:role/deploy
+`
+	if err := os.WriteFile(cfgPath, []byte(cfgYAML), 0644); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadConfig(context.Background(), cfgPath)
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	want := []RegionConfig{
+		{Region: "us-east-1"},
+		{Region: "eu-west-1", Profile: "eu-account"},
+		{Region: "ap-northeast-1", AssumeRoleARN: "arn:aws:iam::333333333333:role/deploy"},
+	}
+	if fmt.Sprint(cfg.Regions) != fmt.Sprint(want) {
+		t.Errorf("Regions = %v, want %v", cfg.Regions, want)
+	}
+
+	app := &App{config: cfg}
+	tests := []struct {
+		region, profile, role string
+	}{
+		{"us-east-1", "", "arn:aws:iam::111111111111:role/deploy"},
+		{"eu-west-1", "eu-account", "arn:aws:iam::111111111111:role/deploy"},
+		{"ap-northeast-1", "", "arn:aws:iam::333333333333:role/deploy"},
+	}
+	for _, tt := range tests {
+		c := app.forRegion(tt.region).config
+		if c.Region != tt.region || c.profile() != tt.profile || c.roleARN() != tt.role {
+			t.Errorf("%s: profile %q role %q, want %q %q", tt.region, c.profile(), c.roleARN(), tt.profile, tt.role)
+		}
+	}
+	// The app itself keeps the first region's settings.
+	if cfg.Region != "us-east-1" || cfg.roleARN() != "arn:aws:iam::111111111111:role/deploy" {
+		t.Errorf("default region settings changed: %q %q", cfg.Region, cfg.roleARN())
+	}
+}
+
+func TestLoadAWSConfig_PerRegionProfile(t *testing.T) {
+	dir := t.TempDir()
+	credentials := `[us-account]
+aws_access_key_id = AKIAUSACCOUNT0000001
+aws_secret_access_key = us-secret
+
+[eu-account]
+aws_access_key_id = AKIAEUACCOUNT0000002
+aws_secret_access_key = eu-secret
+`
+	credsPath := filepath.Join(dir, "credentials")
+	if err := os.WriteFile(credsPath, []byte(credentials), 0600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", credsPath)
+	t.Setenv("AWS_CONFIG_FILE", filepath.Join(dir, "config"))
+	for _, env := range []string{"AWS_ACCESS_KEY_ID", "AWS_SECRET_ACCESS_KEY", "AWS_SESSION_TOKEN", "AWS_PROFILE"} {
+		t.Setenv(env, "")
+		os.Unsetenv(env)
+	}
+
+	app := &App{config: &Config{Regions: []RegionConfig{
+		{Region: "us-east-1", Profile: "us-account"},
+		{Region: "eu-west-1", Profile: "eu-account"},
+	}}}
+	for region, wantKey := range map[string]string{"us-east-1": "AKIAUSACCOUNT0000001", "eu-west-1": "AKIAEUACCOUNT0000002"} {
+		awsCfg, err := loadAWSConfig(context.Background(), app.forRegion(region).config)
+		if err != nil {
+			t.Fatalf("%s: loadAWSConfig failed: %v", region, err)
+		}
+		creds, err := awsCfg.Credentials.Retrieve(context.Background())
+		if err != nil {
+			t.Fatalf("%s: retrieve credentials: %v", region, err)
+		}
+		if creds.AccessKeyID != wantKey || awsCfg.Region != region {
+			t.Errorf("%s: got key %s in %s, want %s", region, creds.AccessKeyID, awsCfg.Region, wantKey)
+		}
+	}
+}
@@ -0,0 +1,114 @@
+package batcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+)
+
+// CopyOption holds options for the copy command.
+type CopyOption struct {
+	// To is the region the job definition is copied to.
+	To string
+	// Name is the job definition name. When set, the template is not
+	// rendered (--no-render).
+	Name string
+	// DryRun prints the differences between the source definition and the
+	// latest active one in the target region, and registers nothing.
+	DryRun bool
+}
+
+// Copy registers the latest active revision of the job definition in the
+// config's region as a new revision in opt.To. Nothing is registered when
+// the target's latest active revision is already identical.
+func (app *App) Copy(ctx context.Context, opt CopyOption) error {
+	from := app.config.Region
+	if opt.To == "" {
+		return fmt.Errorf("a target region is required")
+	}
+	if opt.To == from {
+		return fmt.Errorf("the target region %s is the source region", opt.To)
+	}
+	name, err := app.definitionName(ctx, opt.Name)
+	if err != nil {
+		return err
+	}
+
+	sourceClient, err := app.newBatchClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	source, err := latestActiveDefinition(ctx, sourceClient, name)
+	if err != nil {
+		return err
+	}
+	if source == nil {
+		return fmt.Errorf("no active job definition found for %q in %s", name, from)
+	}
+	sourceMap, err := normalizeRemoteDefinition(*source)
+	if err != nil {
+		return err
+	}
+	sourceLabel := fmt.Sprintf("%s %s:%d", from, name, aws.ToInt32(source.Revision))
+
+	targetClient, err := app.forRegion(opt.To).newBatchClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	target, err := latestActiveDefinition(ctx, targetClient, name)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case target != nil:
+		targetMap, err := normalizeRemoteDefinition(*target)
+		if err != nil {
+			return err
+		}
+		if sameDefinition(targetMap, sourceMap) {
+			fmt.Fprintf(app.out(), "No changes detected. Skip copy. (revision %d in %s is identical)\n", aws.ToInt32(target.Revision), opt.To)
+			return nil
+		}
+		if opt.DryRun {
+			targetLabel := fmt.Sprintf("%s %s:%d", opt.To, name, aws.ToInt32(target.Revision))
+			diff, err := definitionDiff(targetMap, sourceMap, DiffOption{LabelA: targetLabel, LabelB: sourceLabel})
+			if err != nil {
+				return err
+			}
+			fmt.Fprintln(app.out(), diff)
+			return nil
+		}
+	case opt.DryRun:
+		b, err := json.MarshalIndent(sourceMap, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal job definition: %w", err)
+		}
+		fmt.Fprintf(app.out(), "No active job definition found for %q in %s. %s would be newly registered.\n", name, opt.To, sourceLabel)
+		fmt.Fprintln(app.out(), string(b))
+		return nil
+	}
+
+	b, err := json.Marshal(sourceMap)
+	if err != nil {
+		return fmt.Errorf("failed to marshal job definition: %w", err)
+	}
+	var input batch.RegisterJobDefinitionInput
+	if err := json.Unmarshal(b, &input); err != nil {
+		return fmt.Errorf("failed to unmarshal into RegisterJobDefinitionInput: %w", err)
+	}
+	result, err := targetClient.RegisterJobDefinition(ctx, &input)
+	if err != nil {
+		return withHint(fmt.Errorf("failed to register job definition in %s: %w", opt.To, err), opRegister)
+	}
+	fmt.Fprintf(app.out(), "Registered: %s revision %d in %s (copied from %s)\n",
+		aws.ToString(result.JobDefinitionName),
+		aws.ToInt32(result.Revision),
+		opt.To,
+		sourceLabel,
+	)
+	return nil
+}
@@ -0,0 +1,90 @@
+package batcha
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+)
+
+func TestCopy(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "copy-job", "type": "container"}`)
+	definition := func(revision int32, image string) batchTypes.JobDefinition {
+		return batchTypes.JobDefinition{
+			JobDefinitionName:   aws.String("copy-job"),
+			Revision:            aws.Int32(revision),
+			Status:              aws.String("ACTIVE"),
+			Type:                aws.String("container"),
+			ContainerProperties: &batchTypes.ContainerProperties{Image: aws.String(image)},
+		}
+	}
+	describe := func(def batchTypes.JobDefinition) func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+		return func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{JobDefinitions: []batchTypes.JobDefinition{def}}, nil
+		}
+	}
+	var registered []*batch.RegisterJobDefinitionInput
+	target := &fakeBatchClient{
+		describeJobDefinitions: describe(definition(4, "app:v1")),
+		registerJobDefinition: func(in *batch.RegisterJobDefinitionInput) (*batch.RegisterJobDefinitionOutput, error) {
+			registered = append(registered, in)
+			return &batch.RegisterJobDefinitionOutput{JobDefinitionName: in.JobDefinitionName, Revision: aws.Int32(5)}, nil
+		},
+	}
+	app.batchClients = map[string]batchAPI{
+		"us-east-1": &fakeBatchClient{describeJobDefinitions: describe(definition(7, "app:v2"))},
+		"us-west-2": target,
+	}
+	ctx := context.Background()
+
+	// The target was edited by hand: --dry-run shows the diff and registers nothing.
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Copy(ctx, CopyOption{To: "us-west-2", DryRun: true})
+	})
+	if err != nil {
+		t.Fatalf("Copy --dry-run failed: %v", err)
+	}
+	for _, want := range []string{
+		"--- us-west-2 copy-job:4\n",
+		"+++ us-east-1 copy-job:7\n",
+		`-    "Image": "app:v1",`,
+		`+    "Image": "app:v2",`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dry-run output missing %q:\n%s", want, out)
+		}
+	}
+	if len(registered) != 0 {
+		t.Fatalf("--dry-run registered %d definitions", len(registered))
+	}
+
+	out = captureStdout(t, func() {
+		err = app.Copy(ctx, CopyOption{To: "us-west-2"})
+	})
+	if err != nil {
+		t.Fatalf("Copy failed: %v", err)
+	}
+	if len(registered) != 1 || aws.ToString(registered[0].ContainerProperties.Image) != "app:v2" {
+		t.Fatalf("expected the source definition to be registered in the target, got %+v", registered)
+	}
+	if !strings.Contains(out, "Registered: copy-job revision 5 in us-west-2 (copied from us-east-1 copy-job:7)") {
+		t.Errorf("unexpected output: %s", out)
+	}
+
+	// An identical target is left alone.
+	target.describeJobDefinitions = describe(definition(5, "app:v2"))
+	out = captureStdout(t, func() {
+		err = app.Copy(ctx, CopyOption{To: "us-west-2"})
+	})
+	if err != nil || !strings.Contains(out, "Skip copy") || len(registered) != 1 {
+		t.Errorf("expected the copy to be skipped, got %q (%v, %d registered)", out, err, len(registered))
+	}
+
+	if err := app.Copy(ctx, CopyOption{To: "us-east-1"}); err == nil {
+		t.Error("expected an error when copying to the source region")
+	}
+}
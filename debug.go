@@ -0,0 +1,76 @@
+package batcha
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// dumpGoStruct writes v as indented Go-like syntax. Pointers are dereferenced
+// and zero-valued fields are omitted so the output shows exactly which fields
+// the JSON populated.
+func dumpGoStruct(w io.Writer, v any) {
+	var b strings.Builder
+	dumpValue(&b, reflect.ValueOf(v), 0)
+	fmt.Fprintln(w, b.String())
+}
+
+func dumpValue(b *strings.Builder, v reflect.Value, depth int) {
+	indent := strings.Repeat("\t", depth+1)
+	closing := strings.Repeat("\t", depth)
+
+	switch v.Kind() {
+	case reflect.Invalid:
+		b.WriteString("nil")
+	case reflect.Pointer, reflect.Interface:
+		if v.IsNil() {
+			b.WriteString("nil")
+			return
+		}
+		if v.Kind() == reflect.Pointer {
+			b.WriteString("&")
+		}
+		dumpValue(b, v.Elem(), depth)
+	case reflect.Struct:
+		b.WriteString(v.Type().String() + "{\n")
+		for i := 0; i < v.NumField(); i++ {
+			f := v.Type().Field(i)
+			if !f.IsExported() || v.Field(i).IsZero() {
+				continue
+			}
+			b.WriteString(indent + f.Name + ": ")
+			dumpValue(b, v.Field(i), depth+1)
+			b.WriteString(",\n")
+		}
+		b.WriteString(closing + "}")
+	case reflect.Slice, reflect.Array:
+		b.WriteString(v.Type().String() + "{\n")
+		for i := 0; i < v.Len(); i++ {
+			b.WriteString(indent)
+			dumpValue(b, v.Index(i), depth+1)
+			b.WriteString(",\n")
+		}
+		b.WriteString(closing + "}")
+	case reflect.Map:
+		b.WriteString(v.Type().String() + "{\n")
+		// Sort the keys so the dump is stable across runs.
+		keys := make([]string, 0, v.Len())
+		values := map[string]reflect.Value{}
+		for _, k := range v.MapKeys() {
+			key := fmt.Sprintf("%#v", k.Interface())
+			keys = append(keys, key)
+			values[key] = v.MapIndex(k)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			b.WriteString(indent + key + ": ")
+			dumpValue(b, values[key], depth+1)
+			b.WriteString(",\n")
+		}
+		b.WriteString(closing + "}")
+	default:
+		fmt.Fprintf(b, "%#v", v.Interface())
+	}
+}
@@ -0,0 +1,62 @@
+package batcha
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+)
+
+func TestDumpGoStruct(t *testing.T) {
+	input := &batch.RegisterJobDefinitionInput{
+		JobDefinitionName: aws.String("debug-job"),
+		Type:              batchTypes.JobDefinitionTypeContainer,
+		ContainerProperties: &batchTypes.ContainerProperties{
+			Command: []string{"echo", "hi"},
+		},
+	}
+	var b strings.Builder
+	dumpGoStruct(&b, input)
+	out := b.String()
+
+	for _, want := range []string{
+		`JobDefinitionName: &"debug-job"`,
+		`Type: "container"`,
+		`ContainerProperties: &types.ContainerProperties{`,
+		`"echo",`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "Timeout") {
+		t.Errorf("zero fields should be omitted:\n%s", out)
+	}
+}
+
+func TestDumpGoStruct_SortedMaps(t *testing.T) {
+	input := &batch.RegisterJobDefinitionInput{
+		Parameters: map[string]string{"zone": "a", "env": "prod", "mode": "fast", "batch": "1"},
+		Tags:       map[string]string{"team": "data", "app": "etl", "owner": "ops"},
+	}
+	var first strings.Builder
+	dumpGoStruct(&first, input)
+	want := `Parameters: map[string]string{
+		"batch": "1",
+		"env": "prod",
+		"mode": "fast",
+		"zone": "a",
+	},`
+	if !strings.Contains(first.String(), want) {
+		t.Errorf("expected sorted Parameters:\n%s", first.String())
+	}
+	for range 20 {
+		var b strings.Builder
+		dumpGoStruct(&b, input)
+		if b.String() != first.String() {
+			t.Fatalf("dump is not stable:\n%s\nvs\n%s", first.String(), b.String())
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package batcha
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+)
+
+// DeregisterOption holds options for the deregister command.
+type DeregisterOption struct {
+	// Revisions are the revision numbers to deregister.
+	Revisions []int32
+	DryRun    bool
+}
+
+// Deregister deregisters specific revisions of the job definition. Every
+// revision must exist and be ACTIVE; nothing is deregistered otherwise.
+func (app *App) Deregister(ctx context.Context, opt DeregisterOption) error {
+	if len(opt.Revisions) == 0 {
+		return fmt.Errorf("at least one revision is required")
+	}
+	revisions := slices.Clone(opt.Revisions)
+	slices.Sort(revisions)
+	revisions = slices.Compact(revisions)
+
+	name, err := app.jobDefinitionName(ctx)
+	if err != nil {
+		return err
+	}
+
+	client, err := app.newBatchClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	found, missing, err := describeRevisions(ctx, client, name, revisions)
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("revision(s) %v of %q not found", missing, name)
+	}
+	var inactive []int32
+	for _, r := range revisions {
+		if aws.ToString(found[r].Status) != "ACTIVE" {
+			inactive = append(inactive, r)
+		}
+	}
+	if len(inactive) > 0 {
+		return fmt.Errorf("revision(s) %v of %q are not ACTIVE", inactive, name)
+	}
+
+	for _, r := range revisions {
+		arn := aws.ToString(found[r].JobDefinitionArn)
+		if opt.DryRun {
+			fmt.Printf("Would deregister: %s:%d\n", name, r)
+			continue
+		}
+		if _, err := client.DeregisterJobDefinition(ctx, &batch.DeregisterJobDefinitionInput{
+			JobDefinition: aws.String(arn),
+		}); err != nil {
+			return withHint(fmt.Errorf("failed to deregister %s:%d: %w", name, r, err), opDeregister)
+		}
+		fmt.Printf("Deregistered: %s:%d\n", name, r)
+	}
+	return nil
+}
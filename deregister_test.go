@@ -0,0 +1,70 @@
+package batcha
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+)
+
+func TestDeregister(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "old-job", "type": "container"}`)
+	status := map[int32]string{3: "ACTIVE", 5: "ACTIVE", 6: "INACTIVE"}
+	var deregistered []string
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(in *batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			out := &batch.DescribeJobDefinitionsOutput{}
+			for rev, st := range status {
+				id := fmt.Sprintf("old-job:%d", rev)
+				if slices.Contains(in.JobDefinitions, id) {
+					out.JobDefinitions = append(out.JobDefinitions, batchTypes.JobDefinition{
+						JobDefinitionArn: aws.String("arn:aws:batch:us-east-1:123456789012:job-definition/" + id),
+						Revision:         aws.Int32(rev),
+						Status:           aws.String(st),
+					})
+				}
+			}
+			return out, nil
+		},
+		deregisterJobDefinition: func(in *batch.DeregisterJobDefinitionInput) (*batch.DeregisterJobDefinitionOutput, error) {
+			deregistered = append(deregistered, aws.ToString(in.JobDefinition))
+			return &batch.DeregisterJobDefinitionOutput{}, nil
+		},
+	}}
+	ctx := context.Background()
+
+	if err := app.Deregister(ctx, DeregisterOption{Revisions: []int32{3, 5}, DryRun: true}); err != nil {
+		t.Fatalf("dry-run failed: %v", err)
+	}
+	if len(deregistered) != 0 {
+		t.Errorf("dry-run must not deregister, got: %v", deregistered)
+	}
+
+	err := app.Deregister(ctx, DeregisterOption{Revisions: []int32{3, 4, 7}})
+	if err == nil || !strings.Contains(err.Error(), "revision(s) [4 7]") {
+		t.Errorf("expected missing revisions error, got: %v", err)
+	}
+	err = app.Deregister(ctx, DeregisterOption{Revisions: []int32{5, 6}})
+	if err == nil || !strings.Contains(err.Error(), "[6]") || !strings.Contains(err.Error(), "not ACTIVE") {
+		t.Errorf("expected inactive revision error, got: %v", err)
+	}
+	if len(deregistered) != 0 {
+		t.Errorf("validation failures must not deregister, got: %v", deregistered)
+	}
+
+	if err := app.Deregister(ctx, DeregisterOption{Revisions: []int32{5, 3}}); err != nil {
+		t.Fatalf("Deregister failed: %v", err)
+	}
+	want := []string{
+		"arn:aws:batch:us-east-1:123456789012:job-definition/old-job:3",
+		"arn:aws:batch:us-east-1:123456789012:job-definition/old-job:5",
+	}
+	if !slices.Equal(deregistered, want) {
+		t.Errorf("deregistered = %v, want %v", deregistered, want)
+	}
+}
@@ -3,16 +3,126 @@ package batcha
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"maps"
+	"os"
+	"reflect"
+	"regexp"
+	"slices"
+	"strconv"
 	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
 )
 
-// Diff compares the local rendered definition with the active one on AWS.
+// DiffOption holds options for the diff command.
+type DiffOption struct {
+	// LabelA and LabelB are the ---/+++ headers of the diff
+	// (default "remote" and "local").
+	LabelA string
+	LabelB string
+
+	// Compact prints only the changed field paths instead of a unified diff.
+	Compact bool
+	// LineNumbers prefixes each diff line with its remote and local line numbers.
+	LineNumbers bool
+	// Algorithm is the line diff algorithm: "lcs" (default) or "patience".
+	Algorithm string
+	// Format is the output format: "text" (default, a unified diff) or
+	// "json" (an array of change records).
+	Format string
+	// PrintRemote prints the normalized remote definition that the local one
+	// would be compared against, without diffing.
+	PrintRemote bool
+	// PruneDefaults removes server-injected defaults (remoteDefaults and
+	// empty values) that the local definition does not set from the remote
+	// before diffing.
+	PruneDefaults bool
+	// Path scopes the comparison to a dotted camelCase subtree of both
+	// definitions (e.g. "containerProperties.environment").
+	Path string
+}
+
+// Diff algorithms accepted by DiffOption.Algorithm.
+const (
+	diffAlgorithmLCS      = "lcs"
+	diffAlgorithmPatience = "patience"
+)
+
+// Diff compares the local rendered definition with the active one on AWS in
+// every configured region.
 // Returns an error wrapping DiffError if differences exist (exit code 1 for CI).
-func (app *App) Diff(ctx context.Context) error {
+func (app *App) Diff(ctx context.Context, opt DiffOption) error {
+	if opt.Format == "json" && !opt.PrintRemote && len(app.config.Regions) > 1 {
+		return app.diffRegionsJSON(ctx, opt)
+	}
+	return app.eachRegion(func(app *App) error {
+		return app.diff(ctx, opt, printDiffRecords)
+	})
+}
+
+// diffRegionsJSON prints the --format json changes of every region as a
+// single JSON object keyed by region, without the "==> region" headers.
+// Regions that fail are left out of the object and reported together.
+func (app *App) diffRegionsJSON(ctx context.Context, opt DiffOption) error {
+	doc := map[string][]diffRecord{}
+	var (
+		errs  []error
+		diffs int
+	)
+	for _, rc := range app.config.Regions {
+		region := rc.Region
+		err := app.forRegion(region).diff(ctx, opt, func(changes []pathChange) error {
+			doc[region] = diffRecords(changes)
+			return nil
+		})
+		if err == nil {
+			continue
+		}
+		if _, ok := err.(*DiffError); ok {
+			diffs++
+			continue
+		}
+		errs = append(errs, fmt.Errorf("%s: %w", region, err))
+	}
+	if err := printJSON(doc); err != nil {
+		return err
+	}
+	if len(errs) > 0 {
+		return errors.Join(errs...)
+	}
+	if diffs > 0 {
+		return &DiffError{}
+	}
+	return nil
+}
+
+// diff compares the local definition with the remote one in app's region.
+// With --format json, the changes are passed to emit instead of printed.
+func (app *App) diff(ctx context.Context, opt DiffOption, emit func([]pathChange) error) error {
+	if opt.LabelA == "" {
+		opt.LabelA = "remote"
+	}
+	if opt.LabelB == "" {
+		opt.LabelB = "local"
+	}
+	switch opt.Algorithm {
+	case "", diffAlgorithmLCS, diffAlgorithmPatience:
+	default:
+		return fmt.Errorf("unknown diff algorithm %q (expected lcs or patience)", opt.Algorithm)
+	}
+	switch opt.Format {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("unknown diff format %q (expected text or json)", opt.Format)
+	}
+	if opt.Path != "" && !excludePathPattern.MatchString(opt.Path) {
+		return fmt.Errorf("invalid --path %q: expected a key or dotted path such as containerProperties.environment", opt.Path)
+	}
+
 	rendered, err := app.render(ctx)
 	if err != nil {
 		return err
@@ -34,33 +144,410 @@ func (app *App) Diff(ctx context.Context) error {
 		return fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	out, err := client.DescribeJobDefinitions(ctx, &batch.DescribeJobDefinitionsInput{
-		JobDefinitionName: aws.String(name),
-		Status:            aws.String("ACTIVE"),
-	})
+	latest, err := latestActiveDefinition(ctx, client, name)
 	if err != nil {
-		return fmt.Errorf("failed to describe job definitions: %w", err)
+		return err
 	}
 
-	if len(out.JobDefinitions) == 0 {
+	if latest == nil {
+		if opt.PrintRemote {
+			fmt.Printf("No active job definition found for %q.\n", name)
+			return nil
+		}
+		if opt.Format == "json" {
+			// Every top-level field is added.
+			if err := emit(pathDiff(map[string]any{}, walkMap(converted, toCamelCase), "")); err != nil {
+				return err
+			}
+			return &DiffError{}
+		}
 		fmt.Printf("No active job definition found for %q. The local definition will be newly registered.\n", name)
 		fmt.Println(string(localBytes))
 		return &DiffError{}
 	}
 
-	// Pick the latest revision and strip AWS-managed fields
+	// Strip AWS-managed fields
+	remoteMap, err := normalizeRemoteDefinition(*latest)
+	if err != nil {
+		return err
+	}
+	if opt.PruneDefaults {
+		remoteMap = pruneDefaults(remoteMap, converted, "").(map[string]any)
+	}
+
+	var remote, local any = remoteMap, converted
+	remoteCamel, localCamel := walkMap(remoteMap, toCamelCase), walkMap(converted, toCamelCase)
+	if opt.Path != "" {
+		keys := strings.Split(opt.Path, ".")
+		var inRemote, inLocal bool
+		remote, inRemote = subtree(remote, keys)
+		local, inLocal = subtree(local, keys)
+		if !inRemote && !inLocal {
+			return fmt.Errorf("path %q not found in the local or remote definition", opt.Path)
+		}
+		remoteCamel, _ = subtree(remoteCamel, keys)
+		localCamel, _ = subtree(localCamel, keys)
+	}
+
+	if opt.PrintRemote {
+		remoteBytes, err := json.MarshalIndent(remote, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal remote definition: %w", err)
+		}
+		fmt.Println(string(remoteBytes))
+		return nil
+	}
+
+	var changes []pathChange
+	switch {
+	case remoteCamel == nil && localCamel != nil:
+		changes = []pathChange{{'+', opt.Path, nil, localCamel}}
+	case localCamel == nil && remoteCamel != nil:
+		changes = []pathChange{{'-', opt.Path, remoteCamel, nil}}
+	default:
+		changes = pathDiff(remoteCamel, localCamel, opt.Path)
+	}
+	if drift := remoteOnlyPaths(changes); len(drift) > 0 {
+		// stderr keeps --format json parseable.
+		fmt.Fprintf(os.Stderr, "WARNING: remote has changes not in your template (possible console edit): %s\n", strings.Join(drift, ", "))
+	}
+
+	if opt.Format == "json" {
+		if err := emit(changes); err != nil {
+			return err
+		}
+		if len(changes) > 0 {
+			return &DiffError{}
+		}
+		return nil
+	}
+
+	if opt.Compact {
+		if len(changes) == 0 {
+			fmt.Println("No differences found.")
+			return nil
+		}
+		for _, c := range changes {
+			fmt.Println(c)
+		}
+		return &DiffError{}
+	}
+
+	diff, err := definitionDiff(remote, local, opt)
+	if err != nil {
+		return err
+	}
+	if diff == "" {
+		fmt.Println("No differences found.")
+		return nil
+	}
+	fmt.Println(diff)
+	return &DiffError{}
+}
+
+// subtree returns the value at the key path in v. Keys are matched as
+// given, then in PascalCase, so camelCase paths address both template and
+// API-cased definitions.
+func subtree(v any, keys []string) (any, bool) {
+	for _, k := range keys {
+		m, ok := v.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+		child, ok := m[k]
+		if !ok {
+			child, ok = m[toPascalCase(k)]
+		}
+		if !ok || child == nil {
+			return nil, false
+		}
+		v = child
+	}
+	return v, true
+}
+
+// latestActiveDefinition returns the latest ACTIVE revision of name, or nil
+// when there is none.
+func latestActiveDefinition(ctx context.Context, client batchAPI, name string) (*batchTypes.JobDefinition, error) {
+	out, err := client.DescribeJobDefinitions(ctx, &batch.DescribeJobDefinitionsInput{
+		JobDefinitionName: aws.String(name),
+		Status:            aws.String("ACTIVE"),
+	})
+	if err != nil {
+		return nil, withHint(fmt.Errorf("failed to describe job definitions: %w", err), opDescribe)
+	}
+	if len(out.JobDefinitions) == 0 {
+		return nil, nil
+	}
 	latest := pickLatestRevision(out.JobDefinitions)
+	return &latest, nil
+}
+
+// pathChange is a changed JSON path: kind is '~' (modified), '+' (added
+// locally) or '-' (removed locally). from and to are the remote and local
+// values (nil when absent).
+type pathChange struct {
+	kind     byte
+	path     string
+	from, to any
+}
+
+func (c pathChange) String() string { return fmt.Sprintf("%c %s", c.kind, c.path) }
+
+// diffRecord is a change printed by diff --format json.
+type diffRecord struct {
+	Op   string `json:"op"`
+	Path string `json:"path"`
+	From any    `json:"from,omitempty"`
+	To   any    `json:"to,omitempty"`
+}
+
+var diffRecordOps = map[byte]string{'~': "change", '+': "add", '-': "remove"}
+
+// diffRecords converts changes to the records printed by diff --format json.
+func diffRecords(changes []pathChange) []diffRecord {
+	records := make([]diffRecord, 0, len(changes))
+	for _, c := range changes {
+		records = append(records, diffRecord{Op: diffRecordOps[c.kind], Path: c.path, From: c.from, To: c.to})
+	}
+	return records
+}
+
+// printDiffRecords prints changes as a JSON array of diffRecord.
+func printDiffRecords(changes []pathChange) error {
+	return printJSON(diffRecords(changes))
+}
+
+// remoteDefaults are values AWS Batch fills in for fields the template may
+// omit, by leaf path. Node range containers use the containerProperties paths.
+var remoteDefaults = map[string]any{
+	"platformCapabilities": []any{"EC2"},
+	"containerProperties.fargatePlatformConfiguration.platformVersion": "LATEST",
+	"containerProperties.networkConfiguration.assignPublicIp":          "DISABLED",
+	"containerProperties.runtimePlatform.cpuArchitecture":              "X86_64",
+	"containerProperties.runtimePlatform.operatingSystemFamily":        "LINUX",
+}
+
+var nodeContainerPathPattern = regexp.MustCompile(`^nodeProperties\.nodeRangeProperties\[\d+\]\.container\.`)
+
+// remoteOnlyPaths returns the paths of fields present only on the remote
+// definition that look set by a person (e.g. in the console) rather than
+// filled in by AWS: neither empty nor a known default.
+func remoteOnlyPaths(changes []pathChange) []string {
+	var paths []string
+	for _, c := range changes {
+		if c.kind == '-' && !isRemoteDefault(c.path, c.from) {
+			paths = append(paths, c.path)
+		}
+	}
+	return paths
+}
 
-	remoteMap, err := normalizeRemoteDefinition(latest)
+// isRemoteDefault reports whether v at path is empty or made only of
+// remoteDefaults.
+func isRemoteDefault(path string, v any) bool {
+	if isEmptyValue(v) {
+		return true
+	}
+	key := nodeContainerPathPattern.ReplaceAllString(path, "containerProperties.")
+	if def, ok := remoteDefaults[key]; ok && reflect.DeepEqual(def, v) {
+		return true
+	}
+	m, ok := v.(map[string]any)
+	if !ok {
+		return false
+	}
+	for k, child := range m {
+		if !isRemoteDefault(path+"."+k, child) {
+			return false
+		}
+	}
+	return true
+}
+
+// pruneDefaults returns remote without the fields that local does not set and
+// whose value is a server default (see isRemoteDefault). Both are PascalCase
+// definitions; path is the camelCase path of remote.
+func pruneDefaults(remote, local any, path string) any {
+	switch r := remote.(type) {
+	case map[string]any:
+		l, _ := local.(map[string]any)
+		pruned := make(map[string]any, len(r))
+		for k, child := range r {
+			childPath := toCamelCase(k)
+			if path != "" {
+				childPath = path + "." + childPath
+			}
+			localChild, ok := l[k]
+			if !ok || localChild == nil {
+				if isRemoteDefault(childPath, walkMap(child, toCamelCase)) {
+					continue
+				}
+				pruned[k] = child
+				continue
+			}
+			if skipConvertKeys[strings.ToLower(k)] {
+				pruned[k] = child
+				continue
+			}
+			pruned[k] = pruneDefaults(child, localChild, childPath)
+		}
+		return pruned
+	case []any:
+		l, _ := local.([]any)
+		pruned := make([]any, len(r))
+		for i, child := range r {
+			if i < len(l) {
+				pruned[i] = pruneDefaults(child, l[i], fmt.Sprintf("%s[%d]", path, i))
+			} else {
+				pruned[i] = child
+			}
+		}
+		return pruned
+	}
+	return remote
+}
+
+// isEmptyValue reports whether a decoded JSON value is a zero value:
+// false, 0, "", or an empty (or all-empty) object or array.
+func isEmptyValue(v any) bool {
+	switch val := v.(type) {
+	case nil:
+		return true
+	case bool:
+		return !val
+	case float64:
+		return val == 0
+	case string:
+		return val == ""
+	case map[string]any:
+		for _, child := range val {
+			if !isEmptyValue(child) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		for _, child := range val {
+			if !isEmptyValue(child) {
+				return false
+			}
+		}
+		return true
+	}
+	return false
+}
+
+// pathDiff compares two decoded JSON values and returns the changed paths in
+// sorted key order. null is treated the same as an absent key.
+func pathDiff(a, b any, path string) []pathChange {
+	join := func(key string) string {
+		if path == "" {
+			return key
+		}
+		return path + "." + key
+	}
+
+	switch av := a.(type) {
+	case map[string]any:
+		bv, ok := b.(map[string]any)
+		if !ok {
+			break
+		}
+		keys := slices.Collect(maps.Keys(av))
+		for k := range bv {
+			if _, ok := av[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		slices.Sort(keys)
+		var changes []pathChange
+		for _, k := range keys {
+			x, y := av[k], bv[k]
+			switch {
+			case x == nil && y == nil:
+			case x == nil:
+				changes = append(changes, pathChange{'+', join(k), nil, y})
+			case y == nil:
+				changes = append(changes, pathChange{'-', join(k), x, nil})
+			default:
+				changes = append(changes, pathDiff(x, y, join(k))...)
+			}
+		}
+		return changes
+	case []any:
+		bv, ok := b.([]any)
+		if !ok {
+			break
+		}
+		var changes []pathChange
+		for i := range max(len(av), len(bv)) {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(av):
+				changes = append(changes, pathChange{'+', p, nil, bv[i]})
+			case i >= len(bv):
+				changes = append(changes, pathChange{'-', p, av[i], nil})
+			default:
+				changes = append(changes, pathDiff(av[i], bv[i], p)...)
+			}
+		}
+		return changes
+	}
+
+	if reflect.DeepEqual(a, b) {
+		return nil
+	}
+	return []pathChange{{'~', path, a, b}}
+}
+
+// DiffRevisionsOption holds options for the diff-revisions command.
+type DiffRevisionsOption struct {
+	From int32
+	To   int32
+	// Name is the job definition name. When set, the template is not
+	// rendered (--no-render).
+	Name string
+}
+
+// DiffRevisions prints the differences between two registered revisions of
+// the job definition. No local template is compared.
+// Returns DiffError if differences exist.
+func (app *App) DiffRevisions(ctx context.Context, opt DiffRevisionsOption) error {
+	name, err := app.definitionName(ctx, opt.Name)
 	if err != nil {
 		return err
 	}
-	remoteBytes, err := json.MarshalIndent(remoteMap, "", "  ")
+
+	client, err := app.newBatchClient(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to format remote definition: %w", err)
+		return fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	diff := unifiedDiff(string(remoteBytes), string(localBytes), "remote", "local")
+	found, missing, err := describeRevisions(ctx, client, name, []int32{opt.From, opt.To})
+	if err != nil {
+		return err
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("revision(s) %v of %q not found", missing, name)
+	}
+
+	from, err := normalizeRemoteDefinition(found[opt.From])
+	if err != nil {
+		return err
+	}
+	to, err := normalizeRemoteDefinition(found[opt.To])
+	if err != nil {
+		return err
+	}
+
+	diff, err := definitionDiff(from, to, DiffOption{
+		LabelA: fmt.Sprintf("%s:%d", name, opt.From),
+		LabelB: fmt.Sprintf("%s:%d", name, opt.To),
+	})
+	if err != nil {
+		return err
+	}
 	if diff == "" {
 		fmt.Println("No differences found.")
 		return nil
@@ -69,6 +556,21 @@ func (app *App) Diff(ctx context.Context) error {
 	return &DiffError{}
 }
 
+// definitionDiff formats both definitions as indented JSON and returns their
+// unified diff, or an empty string if they are identical. Only the labels and
+// formatting fields of opt are used.
+func definitionDiff(remote, local any, opt DiffOption) (string, error) {
+	remoteBytes, err := json.MarshalIndent(remote, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to format remote definition: %w", err)
+	}
+	localBytes, err := json.MarshalIndent(local, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal local definition: %w", err)
+	}
+	return formatUnifiedDiff(string(remoteBytes), string(localBytes), opt), nil
+}
+
 // DiffError is returned when diff finds differences.
 type DiffError struct{}
 
@@ -79,19 +581,28 @@ func (e *DiffError) Error() string { return "differences found" }
 // unifiedDiff produces a unified diff string between two texts.
 // Returns an empty string if there are no differences.
 func unifiedDiff(a, b, labelA, labelB string) string {
+	return formatUnifiedDiff(a, b, DiffOption{LabelA: labelA, LabelB: labelB})
+}
+
+// formatUnifiedDiff is unifiedDiff with the labels and formatting taken from opt.
+func formatUnifiedDiff(a, b string, opt DiffOption) string {
 	linesA := strings.Split(a, "\n")
 	linesB := strings.Split(b, "\n")
 
-	// Simple LCS-based diff
-	lcs := lcsTable(linesA, linesB)
-	hunks := buildHunks(linesA, linesB, lcs)
+	var ops []diffOp
+	if opt.Algorithm == diffAlgorithmPatience {
+		ops = patienceOps(linesA, linesB)
+	} else {
+		ops = buildOps(linesA, linesB, lcsTable(linesA, linesB))
+	}
+	hunks := buildHunks(ops, opt.LineNumbers)
 	if len(hunks) == 0 {
 		return ""
 	}
 
 	var sb strings.Builder
-	fmt.Fprintf(&sb, "--- %s\n", labelA)
-	fmt.Fprintf(&sb, "+++ %s\n", labelB)
+	fmt.Fprintf(&sb, "--- %s\n", opt.LabelA)
+	fmt.Fprintf(&sb, "+++ %s\n", opt.LabelB)
 	for _, h := range hunks {
 		sb.WriteString(h)
 	}
@@ -125,8 +636,7 @@ type diffOp struct {
 	posB int
 }
 
-func buildHunks(a, b []string, lcs [][]int) []string {
-	ops := buildOps(a, b, lcs)
+func buildHunks(ops []diffOp, lineNumbers bool) []string {
 	if len(ops) == 0 {
 		return nil
 	}
@@ -157,7 +667,7 @@ func buildHunks(a, b []string, lcs [][]int) []string {
 			} else if i-lastChange > 2*ctx {
 				// Flush previous hunk
 				end := min(lastChange+ctx+1, len(ops))
-				hunks = append(hunks, formatHunk(append(hunkOps, ops[lastChange+1:end]...)))
+				hunks = append(hunks, formatHunk(append(hunkOps, ops[lastChange+1:end]...), lineNumbers))
 				// Start new hunk
 				start := max(i-ctx, 0)
 				hunkOps = make([]diffOp, 0)
@@ -176,7 +686,7 @@ func buildHunks(a, b []string, lcs [][]int) []string {
 	// Flush final hunk
 	if lastChange >= 0 {
 		end := min(lastChange+ctx+1, len(ops))
-		hunks = append(hunks, formatHunk(append(hunkOps, ops[lastChange+1:end]...)))
+		hunks = append(hunks, formatHunk(append(hunkOps, ops[lastChange+1:end]...), lineNumbers))
 	}
 
 	return hunks
@@ -207,7 +717,98 @@ func buildOps(a, b []string, lcs [][]int) []diffOp {
 	return ops
 }
 
-func formatHunk(ops []diffOp) string {
+// patienceOps diffs a and b with the patience algorithm: lines that occur
+// exactly once on both sides are matched first (keeping the longest run in
+// order), and the gaps between them are diffed recursively. Gaps without
+// unique lines fall back to LCS. Moved blocks then show up as one removal and
+// one addition instead of being interleaved with unrelated lines.
+func patienceOps(a, b []string) []diffOp {
+	var ops []diffOp
+	var diffRange func(aLo, aHi, bLo, bHi int)
+	diffRange = func(aLo, aHi, bLo, bHi int) {
+		if aLo == aHi && bLo == bHi {
+			return
+		}
+		anchors := uniqueAnchors(a[aLo:aHi], b[bLo:bHi])
+		if len(anchors) == 0 {
+			for _, op := range buildOps(a[aLo:aHi], b[bLo:bHi], lcsTable(a[aLo:aHi], b[bLo:bHi])) {
+				op.posA += aLo
+				op.posB += bLo
+				ops = append(ops, op)
+			}
+			return
+		}
+		i, j := aLo, bLo
+		for _, m := range anchors {
+			ai, bj := aLo+m[0], bLo+m[1]
+			diffRange(i, ai, j, bj)
+			ops = append(ops, diffOp{' ', a[ai], ai, bj})
+			i, j = ai+1, bj+1
+		}
+		diffRange(i, aHi, j, bHi)
+	}
+	diffRange(0, len(a), 0, len(b))
+	return ops
+}
+
+// uniqueAnchors returns index pairs of lines that occur exactly once in both
+// a and b, reduced to the longest sequence increasing on both sides.
+func uniqueAnchors(a, b []string) [][2]int {
+	type occurrence struct{ countA, countB, posA, posB int }
+	occ := map[string]*occurrence{}
+	for i, line := range a {
+		o, ok := occ[line]
+		if !ok {
+			o = &occurrence{}
+			occ[line] = o
+		}
+		o.countA++
+		o.posA = i
+	}
+	for j, line := range b {
+		if o, ok := occ[line]; ok {
+			o.countB++
+			o.posB = j
+		}
+	}
+	var matches [][2]int
+	for i, line := range a {
+		if o := occ[line]; o.countA == 1 && o.countB == 1 {
+			matches = append(matches, [2]int{i, o.posB})
+		}
+	}
+
+	// Longest increasing subsequence on the b index (patience sorting).
+	var tails []int // index into matches of the smallest tail per length
+	prev := make([]int, len(matches))
+	for k, m := range matches {
+		n, _ := slices.BinarySearchFunc(tails, m[1], func(t, target int) int {
+			return matches[t][1] - target
+		})
+		if n > 0 {
+			prev[k] = tails[n-1]
+		} else {
+			prev[k] = -1
+		}
+		if n == len(tails) {
+			tails = append(tails, k)
+		} else {
+			tails[n] = k
+		}
+	}
+	if len(tails) == 0 {
+		return nil
+	}
+	lis := make([][2]int, len(tails))
+	for k, n := tails[len(tails)-1], len(tails)-1; n >= 0; k, n = prev[k], n-1 {
+		lis[n] = matches[k]
+	}
+	return lis
+}
+
+// formatHunk formats a hunk. With lineNumbers, each line is prefixed with
+// its 1-based line number in a and b (blank on the side it is absent from).
+func formatHunk(ops []diffOp, lineNumbers bool) string {
 	if len(ops) == 0 {
 		return ""
 	}
@@ -228,6 +829,16 @@ func formatHunk(ops []diffOp) string {
 	var sb strings.Builder
 	fmt.Fprintf(&sb, "@@ -%d,%d +%d,%d @@\n", startA, countA, startB, countB)
 	for _, op := range ops {
+		if lineNumbers {
+			numA, numB := strconv.Itoa(op.posA+1), strconv.Itoa(op.posB+1)
+			switch op.kind {
+			case '-':
+				numB = ""
+			case '+':
+				numA = ""
+			}
+			fmt.Fprintf(&sb, "%4s %4s ", numA, numB)
+		}
 		fmt.Fprintf(&sb, "%c%s\n", op.kind, op.line)
 	}
 	return sb.String()
@@ -1,8 +1,17 @@
 package batcha
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"slices"
 	"strings"
 	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
 )
 
 func TestUnifiedDiff_NoDiff(t *testing.T) {
@@ -29,3 +38,439 @@ func TestUnifiedDiff_WithChanges(t *testing.T) {
 		t.Errorf("diff missing expected lines:\n%s", diff)
 	}
 }
+
+func TestUnifiedDiff_Labels(t *testing.T) {
+	diff := unifiedDiff("a", "b", "prod", "staging")
+	if !strings.HasPrefix(diff, "--- prod\n+++ staging\n") {
+		t.Errorf("diff headers should use the given labels:\n%s", diff)
+	}
+}
+
+func TestUnifiedDiff_LineNumbers(t *testing.T) {
+	a := "l1\nl2\nl3\nl4"
+	b := "l1\nl3\nnew\nl4"
+	diff := formatUnifiedDiff(a, b, DiffOption{LabelA: "a", LabelB: "b", LineNumbers: true})
+
+	// Each op is prefixed with posA+1 / posB+1; the absent side is blank.
+	for _, want := range []string{
+		"   1    1  l1\n",
+		"   2      -l2\n",
+		"   3    2  l3\n",
+		"        3 +new\n",
+		"   4    4  l4\n",
+	} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+}
+
+func TestDiffRevisions(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "rev-job", "type": "container"}`)
+	defs := map[string]batchTypes.JobDefinition{
+		"rev-job:5": {
+			JobDefinitionName:   aws.String("rev-job"),
+			Revision:            aws.Int32(5),
+			Status:              aws.String("INACTIVE"),
+			ContainerProperties: &batchTypes.ContainerProperties{Image: aws.String("app:good")},
+		},
+		"rev-job:7": {
+			JobDefinitionName:   aws.String("rev-job"),
+			Revision:            aws.Int32(7),
+			Status:              aws.String("ACTIVE"),
+			ContainerProperties: &batchTypes.ContainerProperties{Image: aws.String("app:bad")},
+		},
+	}
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(in *batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			out := &batch.DescribeJobDefinitionsOutput{}
+			for _, id := range in.JobDefinitions {
+				if d, ok := defs[id]; ok {
+					out.JobDefinitions = append(out.JobDefinitions, d)
+				}
+			}
+			return out, nil
+		},
+	}}
+
+	err := app.DiffRevisions(context.Background(), DiffRevisionsOption{From: 5, To: 7})
+	if _, ok := err.(*DiffError); !ok {
+		t.Errorf("expected DiffError, got: %v", err)
+	}
+
+	err = app.DiffRevisions(context.Background(), DiffRevisionsOption{From: 5, To: 8})
+	if err == nil || !strings.Contains(err.Error(), "[8]") {
+		t.Errorf("expected missing revision error, got: %v", err)
+	}
+}
+
+func TestDiff_PrintRemote(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "remote-job", "type": "container", "containerProperties": {"image": "app:local"}}`)
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{JobDefinitions: []batchTypes.JobDefinition{{
+				JobDefinitionArn:           aws.String("arn:aws:batch:us-east-1:123456789012:job-definition/remote-job:4"),
+				JobDefinitionName:          aws.String("remote-job"),
+				Revision:                   aws.Int32(4),
+				Status:                     aws.String("ACTIVE"),
+				Type:                       aws.String("container"),
+				ContainerOrchestrationType: batchTypes.OrchestrationTypeEcs,
+				ContainerProperties:        &batchTypes.ContainerProperties{Image: aws.String("app:remote")},
+			}}}, nil
+		},
+	}}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Diff(context.Background(), DiffOption{PrintRemote: true})
+	})
+	if err != nil {
+		t.Fatalf("expected exit 0 with --print-remote despite differences, got: %v", err)
+	}
+	if !strings.Contains(out, `"Image": "app:remote"`) {
+		t.Errorf("expected the remote definition, got:\n%s", out)
+	}
+	for _, managed := range []string{"JobDefinitionArn", "Revision", "Status", "ContainerOrchestrationType", "---", "app:local"} {
+		if strings.Contains(out, managed) {
+			t.Errorf("output must not contain %q:\n%s", managed, out)
+		}
+	}
+}
+
+func TestDiff_RemoteOnlyDrift(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "drift-job", "type": "container", "containerProperties": {"image": "app:v1"}}`)
+	remote := batchTypes.JobDefinition{
+		JobDefinitionName:    aws.String("drift-job"),
+		Revision:             aws.Int32(2),
+		Type:                 aws.String("container"),
+		PlatformCapabilities: []batchTypes.PlatformCapability{batchTypes.PlatformCapabilityEc2},
+		ContainerProperties: &batchTypes.ContainerProperties{
+			Image:       aws.String("app:v1"),
+			Environment: []batchTypes.KeyValuePair{},
+		},
+	}
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{JobDefinitions: []batchTypes.JobDefinition{remote}}, nil
+		},
+	}}
+
+	// Only server defaults and empty values on the remote side.
+	var err error
+	stderr := captureStderr(t, func() {
+		captureStdout(t, func() { err = app.Diff(context.Background(), DiffOption{Compact: true}) })
+	})
+	if strings.Contains(stderr, "WARNING") {
+		t.Errorf("server defaults must not be reported as drift:\n%s", stderr)
+	}
+
+	remote.ContainerProperties.Environment = []batchTypes.KeyValuePair{{Name: aws.String("DEBUG"), Value: aws.String("1")}}
+	stderr = captureStderr(t, func() {
+		captureStdout(t, func() { err = app.Diff(context.Background(), DiffOption{Compact: true}) })
+	})
+	var diffErr *DiffError
+	if !errors.As(err, &diffErr) {
+		t.Errorf("expected DiffError, got: %v", err)
+	}
+	if !strings.Contains(stderr, "WARNING: remote has changes not in your template (possible console edit): containerProperties.environment") {
+		t.Errorf("expected a drift warning, got:\n%s", stderr)
+	}
+}
+
+func TestDiff_PruneDefaults(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "prune-job", "type": "container", "containerProperties": {"image": "app:v1"}}`)
+	remote := batchTypes.JobDefinition{
+		JobDefinitionName:    aws.String("prune-job"),
+		Revision:             aws.Int32(4),
+		Type:                 aws.String("container"),
+		PlatformCapabilities: []batchTypes.PlatformCapability{batchTypes.PlatformCapabilityEc2},
+		ContainerProperties: &batchTypes.ContainerProperties{
+			Image:                        aws.String("app:v1"),
+			Privileged:                   aws.Bool(false),
+			Environment:                  []batchTypes.KeyValuePair{},
+			FargatePlatformConfiguration: &batchTypes.FargatePlatformConfiguration{PlatformVersion: aws.String("LATEST")},
+		},
+	}
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{JobDefinitions: []batchTypes.JobDefinition{remote}}, nil
+		},
+	}}
+
+	var err error
+	out := captureStdout(t, func() { err = app.Diff(context.Background(), DiffOption{Compact: true}) })
+	if _, ok := err.(*DiffError); !ok {
+		t.Fatalf("expected server defaults to be reported without --prune-defaults, got: %v", err)
+	}
+	for _, want := range []string{"- platformCapabilities", "- containerProperties.privileged", "- containerProperties.fargatePlatformConfiguration"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("expected %q in the diff, got:\n%s", want, out)
+		}
+	}
+
+	for _, opt := range []DiffOption{{PruneDefaults: true}, {PruneDefaults: true, Compact: true}} {
+		out = captureStdout(t, func() { err = app.Diff(context.Background(), opt) })
+		if err != nil || !strings.Contains(out, "No differences found.") {
+			t.Errorf("expected no differences with %+v, got %v:\n%s", opt, err, out)
+		}
+	}
+
+	// Fields set locally and real changes are kept.
+	remote.ContainerProperties.Image = aws.String("app:v0")
+	out = captureStdout(t, func() { err = app.Diff(context.Background(), DiffOption{PruneDefaults: true, Compact: true}) })
+	if _, ok := err.(*DiffError); !ok || strings.TrimSpace(out) != "~ containerProperties.image" {
+		t.Errorf("expected only the image change, got %v:\n%s", err, out)
+	}
+}
+
+func TestDiff_Path(t *testing.T) {
+	app := verifyApp(t, `{
+  "jobDefinitionName": "path-job",
+  "type": "container",
+  "containerProperties": {
+    "image": "app:v2",
+    "environment": [{"name": "STAGE", "value": "prod"}, {"name": "DEBUG", "value": "0"}]
+  }
+}`)
+	remote := batchTypes.JobDefinition{
+		JobDefinitionName: aws.String("path-job"),
+		Revision:          aws.Int32(1),
+		Type:              aws.String("container"),
+		ContainerProperties: &batchTypes.ContainerProperties{
+			Image: aws.String("app:v1"),
+			Environment: []batchTypes.KeyValuePair{
+				{Name: aws.String("STAGE"), Value: aws.String("prod")},
+				{Name: aws.String("DEBUG"), Value: aws.String("1")},
+			},
+		},
+	}
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{JobDefinitions: []batchTypes.JobDefinition{remote}}, nil
+		},
+	}}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Diff(context.Background(), DiffOption{Path: "containerProperties.environment"})
+	})
+	if _, ok := err.(*DiffError); !ok {
+		t.Fatalf("expected DiffError, got: %v", err)
+	}
+	if !strings.Contains(out, `-    "Value": "1"`) || !strings.Contains(out, `+    "Value": "0"`) {
+		t.Errorf("expected the environment change, got:\n%s", out)
+	}
+	if strings.Contains(out, "app:v") || strings.Contains(out, "JobDefinitionName") {
+		t.Errorf("expected the diff to be scoped to containerProperties.environment, got:\n%s", out)
+	}
+
+	out = captureStdout(t, func() {
+		err = app.Diff(context.Background(), DiffOption{Path: "containerProperties.environment", Compact: true})
+	})
+	if strings.TrimSpace(out) != "~ containerProperties.environment[1].value" {
+		t.Errorf("expected full paths in the compact diff, got:\n%s", out)
+	}
+
+	// The image differs, but the scoped subtree does not.
+	remote.ContainerProperties.Environment[1].Value = aws.String("0")
+	out = captureStdout(t, func() {
+		err = app.Diff(context.Background(), DiffOption{Path: "containerProperties.environment"})
+	})
+	if err != nil || !strings.Contains(out, "No differences found.") {
+		t.Errorf("expected no differences in the subtree, got %v:\n%s", err, out)
+	}
+
+	err = app.Diff(context.Background(), DiffOption{Path: "containerProperties.linuxParameters"})
+	if err == nil || !strings.Contains(err.Error(), `path "containerProperties.linuxParameters" not found`) {
+		t.Errorf("expected an error for a path absent on both sides, got: %v", err)
+	}
+}
+
+func TestPathDiff(t *testing.T) {
+	remote := map[string]any{
+		"containerProperties": map[string]any{
+			"image":       "app:v1",
+			"environment": []any{map[string]any{"name": "A"}, map[string]any{"name": "B"}},
+			"user":        nil,
+		},
+		"tags": map[string]any{"old": "x", "team": "data"},
+	}
+	local := map[string]any{
+		"containerProperties": map[string]any{
+			"image":       "app:v2",
+			"environment": []any{map[string]any{"name": "A"}, map[string]any{"name": "B"}, map[string]any{"name": "C"}},
+		},
+		"tags": map[string]any{"team": "data"},
+	}
+
+	var got []string
+	for _, c := range pathDiff(remote, local, "") {
+		got = append(got, c.String())
+	}
+	want := []string{
+		"+ containerProperties.environment[2]",
+		"~ containerProperties.image",
+		"- tags.old",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("pathDiff =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	if changes := pathDiff(remote, remote, ""); len(changes) != 0 {
+		t.Errorf("expected no changes for identical values, got: %v", changes)
+	}
+}
+
+func TestUnifiedDiff_Patience(t *testing.T) {
+	// DEBUG is added in front of INPUT and RETRIES is removed. LCS matches the
+	// "name"/"value" structure positionally and rewrites both entries;
+	// patience anchors on the unique INPUT lines and keeps them as context.
+	a := strings.Join([]string{
+		"[", "  {", `    "name": "INPUT",`, `    "value": "s3://bucket/in"`, "  },",
+		"  {", `    "name": "RETRIES",`, `    "value": "3"`, "  }", "]",
+	}, "\n")
+	b := strings.Join([]string{
+		"[", "  {", `    "name": "DEBUG",`, `    "value": "1"`, "  },",
+		"  {", `    "name": "INPUT",`, `    "value": "s3://bucket/in"`, "  }", "]",
+	}, "\n")
+
+	lcs := formatUnifiedDiff(a, b, DiffOption{LabelA: "a", LabelB: "b"})
+	if !strings.Contains(lcs, `-    "name": "INPUT",`) {
+		t.Errorf("expected the LCS diff to rewrite INPUT:\n%s", lcs)
+	}
+
+	diff := formatUnifiedDiff(a, b, DiffOption{LabelA: "a", LabelB: "b", Algorithm: diffAlgorithmPatience})
+	want := "--- a\n+++ b\n@@ -1,10 +1,10 @@\n" +
+		" [\n" +
+		"   {\n" +
+		`+    "name": "DEBUG",` + "\n" +
+		`+    "value": "1"` + "\n" +
+		"+  },\n" +
+		"+  {\n" +
+		`     "name": "INPUT",` + "\n" +
+		`     "value": "s3://bucket/in"` + "\n" +
+		"-  },\n" +
+		"-  {\n" +
+		`-    "name": "RETRIES",` + "\n" +
+		`-    "value": "3"` + "\n" +
+		"   }\n" +
+		" ]\n"
+	if diff != want {
+		t.Errorf("patience diff =\n%s\nwant\n%s", diff, want)
+	}
+}
+
+func TestPatienceOps_NoUniqueLines(t *testing.T) {
+	// Without unique lines patience falls back to LCS; the ops must still
+	// reproduce both inputs.
+	a := []string{"x", "x", "y", "y", "x"}
+	b := []string{"x", "y", "y", "x", "x"}
+	var gotA, gotB []string
+	for _, op := range patienceOps(a, b) {
+		if op.kind != '+' {
+			gotA = append(gotA, op.line)
+		}
+		if op.kind != '-' {
+			gotB = append(gotB, op.line)
+		}
+	}
+	if !slices.Equal(gotA, a) || !slices.Equal(gotB, b) {
+		t.Errorf("ops do not reproduce the inputs: a=%v b=%v", gotA, gotB)
+	}
+}
+
+func TestDiff_FormatJSON(t *testing.T) {
+	app := verifyApp(t, `{
+  "jobDefinitionName": "json-diff-job",
+  "type": "container",
+  "containerProperties": {"image": "app:v2", "command": ["run"]}
+}`)
+	remote := batchTypes.JobDefinition{
+		JobDefinitionName: aws.String("json-diff-job"),
+		Revision:          aws.Int32(1),
+		Type:              aws.String("container"),
+		Tags:              map[string]string{"team": "data"},
+		ContainerProperties: &batchTypes.ContainerProperties{
+			Image: aws.String("app:v1"),
+		},
+	}
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{JobDefinitions: []batchTypes.JobDefinition{remote}}, nil
+		},
+	}}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Diff(context.Background(), DiffOption{Format: "json"})
+	})
+	if _, ok := err.(*DiffError); !ok {
+		t.Errorf("expected DiffError, got: %v", err)
+	}
+	var records []map[string]any
+	if err := json.Unmarshal([]byte(out), &records); err != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", err, out)
+	}
+	want := []map[string]any{
+		{"op": "add", "path": "containerProperties.command", "to": []any{"run"}},
+		{"op": "change", "path": "containerProperties.image", "from": "app:v1", "to": "app:v2"},
+		{"op": "remove", "path": "tags", "from": map[string]any{"team": "data"}},
+	}
+	if !reflect.DeepEqual(records, want) {
+		t.Errorf("records = %v, want %v", records, want)
+	}
+
+	// No differences: an empty array and exit 0.
+	remote.Tags = nil
+	remote.ContainerProperties = &batchTypes.ContainerProperties{Image: aws.String("app:v2"), Command: []string{"run"}}
+	out = captureStdout(t, func() {
+		err = app.Diff(context.Background(), DiffOption{Format: "json"})
+	})
+	if err != nil || strings.TrimSpace(out) != "[]" {
+		t.Errorf("expected [] and no error, got %q (%v)", out, err)
+	}
+}
+
+func TestDiff_FormatJSONRegions(t *testing.T) {
+	app := verifyApp(t, `{
+  "jobDefinitionName": "json-diff-job",
+  "type": "container",
+  "containerProperties": {"image": "app:v2"}
+}`)
+	app.config.Regions = []RegionConfig{{Region: "us-east-1"}, {Region: "us-west-2"}}
+	clientWithImage := func(image string) batchAPI {
+		return &fakeBatchClient{
+			describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+				return &batch.DescribeJobDefinitionsOutput{JobDefinitions: []batchTypes.JobDefinition{{
+					JobDefinitionName:   aws.String("json-diff-job"),
+					Revision:            aws.Int32(1),
+					Type:                aws.String("container"),
+					ContainerProperties: &batchTypes.ContainerProperties{Image: aws.String(image)},
+				}}}, nil
+			},
+		}
+	}
+	app.batchClients = map[string]batchAPI{
+		"us-east-1": clientWithImage("app:v1"),
+		"us-west-2": clientWithImage("app:v2"),
+	}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Diff(context.Background(), DiffOption{Format: "json"})
+	})
+	if _, ok := err.(*DiffError); !ok {
+		t.Errorf("expected DiffError, got: %v", err)
+	}
+	var doc map[string][]map[string]any
+	if err := json.Unmarshal([]byte(out), &doc); err != nil {
+		t.Fatalf("output is not a single JSON object: %v\n%s", err, out)
+	}
+	want := map[string][]map[string]any{
+		"us-east-1": {{"op": "change", "path": "containerProperties.image", "from": "app:v1", "to": "app:v2"}},
+		"us-west-2": {},
+	}
+	if !reflect.DeepEqual(doc, want) {
+		t.Errorf("doc = %v, want %v", doc, want)
+	}
+}
@@ -0,0 +1,72 @@
+package batcha
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// defaultExecTimeout bounds each exec plugin run unless timeout is set.
+const defaultExecTimeout = 30 * time.Second
+
+// execFuncMap returns the exec template function. It runs cfg.Command with
+// the template arguments appended and returns its stdout without the
+// trailing newline. Results are cached per arguments for the lifetime of
+// the FuncMap.
+func execFuncMap(ctx context.Context, cfg PluginConfig) (template.FuncMap, error) {
+	if len(cfg.Command) == 0 || cfg.Command[0] == "" {
+		return nil, fmt.Errorf("command is required")
+	}
+	timeout := defaultExecTimeout
+	if cfg.Timeout != "" {
+		d, err := time.ParseDuration(cfg.Timeout)
+		if err != nil || d <= 0 {
+			return nil, fmt.Errorf("invalid timeout %q", cfg.Timeout)
+		}
+		timeout = d
+	}
+	cache := map[string]string{}
+	return template.FuncMap{
+		"exec": func(args ...string) (string, error) {
+			argv := append(cfg.Command[:len(cfg.Command):len(cfg.Command)], args...)
+			key := strings.Join(argv, "\x00")
+			if v, ok := cache[key]; ok {
+				return v, nil
+			}
+			v, err := runExec(ctx, argv, timeout)
+			if err != nil {
+				return "", err
+			}
+			cache[key] = v
+			return v, nil
+		},
+	}, nil
+}
+
+// runExec runs argv without a shell. stderr is included in the error when
+// the command fails.
+func runExec(ctx context.Context, argv []string, timeout time.Duration) (string, error) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	// Do not wait forever on output pipes inherited by the command's children.
+	cmd.WaitDelay = time.Second
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() == context.DeadlineExceeded {
+			return "", fmt.Errorf("exec %s timed out after %s", argv[0], timeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("exec %s failed: %w: %s", argv[0], err, msg)
+		}
+		return "", fmt.Errorf("exec %s failed: %w", argv[0], err)
+	}
+	return strings.TrimRight(stdout.String(), "\r\n"), nil
+}
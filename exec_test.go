@@ -0,0 +1,60 @@
+package batcha
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExecPlugin(t *testing.T) {
+	dir := t.TempDir()
+	jobDef := `{"jobDefinitionName": "{{ exec "world" }}", "type": "container"}`
+	if err := os.WriteFile(filepath.Join(dir, "job.json"), []byte(jobDef), 0644); err != nil {
+		t.Fatal(err)
+	}
+	config := "region: us-east-1\njob_definition: job.json\nplugins:\n  - name: exec\n    config:\n      command: [echo, hello]\n"
+	if err := os.WriteFile(filepath.Join(dir, "batcha.yml"), []byte(config), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	app, err := New(context.Background(), filepath.Join(dir, "batcha.yml"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	rendered, err := app.render(context.Background())
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	if got := rendered["jobDefinitionName"]; got != "hello world" {
+		t.Errorf("jobDefinitionName = %v, want %q", got, "hello world")
+	}
+}
+
+func TestExecFuncMap_Errors(t *testing.T) {
+	if _, err := execFuncMap(context.Background(), PluginConfig{}); err == nil {
+		t.Error("expected an error without command")
+	}
+	if _, err := execFuncMap(context.Background(), PluginConfig{Command: []string{"echo"}, Timeout: "soon"}); err == nil {
+		t.Error("expected an error for an invalid timeout")
+	}
+
+	funcMap, err := execFuncMap(context.Background(), PluginConfig{Command: []string{"sh", "-c", "echo oops >&2; exit 3"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = funcMap["exec"].(func(...string) (string, error))()
+	if err == nil || !strings.Contains(err.Error(), "exit status 3: oops") {
+		t.Errorf("expected the command's stderr in the error, got: %v", err)
+	}
+
+	funcMap, err = execFuncMap(context.Background(), PluginConfig{Command: []string{"sleep"}, Timeout: "100ms"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = funcMap["exec"].(func(...string) (string, error))("5")
+	if err == nil || !strings.Contains(err.Error(), "timed out after 100ms") {
+		t.Errorf("expected a timeout error, got: %v", err)
+	}
+}
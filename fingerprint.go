@@ -0,0 +1,36 @@
+package batcha
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+)
+
+// Fingerprint renders the job definition and prints its fingerprint.
+func (app *App) Fingerprint(ctx context.Context) error {
+	rendered, err := app.render(ctx)
+	if err != nil {
+		return err
+	}
+	fp, err := fingerprint(rendered)
+	if err != nil {
+		return err
+	}
+	fmt.Println(fp)
+	return nil
+}
+
+// fingerprint returns the hex SHA-256 of the definition as sent to AWS
+// (PascalCase keys) in canonical form: compact JSON with object keys in
+// sorted order, so equal definitions match whatever their key order.
+func fingerprint(rendered map[string]any) (string, error) {
+	// encoding/json writes map keys in sorted order.
+	b, err := json.Marshal(walkMap(rendered, toPascalCase))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal job definition: %w", err)
+	}
+	sum := sha256.Sum256(b)
+	return hex.EncodeToString(sum[:]), nil
+}
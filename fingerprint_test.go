@@ -0,0 +1,48 @@
+package batcha
+
+import (
+	"context"
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestFingerprint(t *testing.T) {
+	a := verifyApp(t, `{
+  "jobDefinitionName": "fp-job",
+  "type": "container",
+  "containerProperties": {"image": "nginx", "command": ["echo", "hi"]},
+  "tags": {"team": "data", "env": "prod"}
+}`)
+	b := verifyApp(t, `{
+  "tags": {"env": "prod", "team": "data"},
+  "containerProperties": {"command": ["echo", "hi"], "image": "nginx"},
+  "type": "container",
+  "jobDefinitionName": "fp-job"
+}`)
+	c := verifyApp(t, `{
+  "jobDefinitionName": "fp-job",
+  "type": "container",
+  "containerProperties": {"image": "nginx", "command": ["hi", "echo"]},
+  "tags": {"team": "data", "env": "prod"}
+}`)
+
+	fingerprints := make([]string, 3)
+	for i, app := range []*App{a, b, c} {
+		out := captureStdout(t, func() {
+			if err := app.Fingerprint(context.Background()); err != nil {
+				t.Fatalf("Fingerprint failed: %v", err)
+			}
+		})
+		fingerprints[i] = strings.TrimSpace(out)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{64}$`).MatchString(fingerprints[0]) {
+		t.Errorf("fingerprint %q is not a hex SHA-256", fingerprints[0])
+	}
+	if fingerprints[0] != fingerprints[1] {
+		t.Errorf("reordered keys changed the fingerprint: %s != %s", fingerprints[0], fingerprints[1])
+	}
+	if fingerprints[0] == fingerprints[2] {
+		t.Error("a different command order must change the fingerprint")
+	}
+}
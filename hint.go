@@ -0,0 +1,73 @@
+package batcha
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/aws/smithy-go"
+)
+
+// awsOperation identifies the kind of AWS call an error came from, so hints
+// can be specific.
+type awsOperation string
+
+const (
+	opDescribe   awsOperation = "describe"
+	opRegister   awsOperation = "register"
+	opSubmit     awsOperation = "submit"
+	opDeregister awsOperation = "deregister"
+)
+
+// HintError wraps an AWS API error with an actionable remediation hint.
+type HintError struct {
+	Err  error
+	Hint string
+}
+
+func (e *HintError) Error() string { return fmt.Sprintf("%s\nhint: %s", e.Err, e.Hint) }
+
+func (e *HintError) Unwrap() error { return e.Err }
+
+// withHint wraps err in a HintError when it carries a well-known AWS error
+// code. Other errors are returned unchanged.
+func withHint(err error, op awsOperation) error {
+	var apiErr smithy.APIError
+	if !errors.As(err, &apiErr) {
+		return err
+	}
+	hint := awsErrorHint(apiErr.ErrorCode(), op)
+	if hint == "" {
+		return err
+	}
+	return &HintError{Err: err, Hint: hint}
+}
+
+func awsErrorHint(code string, op awsOperation) string {
+	switch code {
+	case "AccessDeniedException", "AccessDenied", "UnauthorizedOperation":
+		switch op {
+		case opRegister:
+			return "the caller lacks batch:RegisterJobDefinition, or iam:PassRole on the jobRoleArn/executionRoleArn"
+		case opSubmit:
+			return "the caller lacks batch:SubmitJob on the job queue and job definition"
+		case opDeregister:
+			return "the caller lacks batch:DeregisterJobDefinition on the job definition"
+		}
+		return "the caller lacks batch:Describe* permissions; check the credentials or role_arn in use"
+	case "UnrecognizedClientException", "InvalidClientTokenId", "ExpiredToken", "ExpiredTokenException":
+		return "the AWS credentials are invalid or expired; refresh them or check the profile/role in use"
+	case "ClientException":
+		switch op {
+		case opRegister:
+			return "AWS rejected the job definition; run `batcha verify` and check that jobRoleArn/executionRoleArn exist (and that the execution role can pull the image from ECR)"
+		case opSubmit:
+			return "check that the job queue exists in this region and is ENABLED/VALID, and that the job definition revision is ACTIVE"
+		}
+		return "check the job definition name and the configured region"
+	case "ThrottlingException", "TooManyRequestsException", "Throttling":
+		return "the request was throttled; retry later or reduce concurrency"
+	case "ServerException":
+		return "AWS Batch returned an internal error; retry the command"
+	}
+	return ""
+}
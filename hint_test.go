@@ -0,0 +1,39 @@
+package batcha
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/smithy-go"
+)
+
+func TestWithHint(t *testing.T) {
+	apiErr := &smithy.GenericAPIError{Code: "ClientException", Message: "JobQueue my-queue not found"}
+	err := withHint(fmt.Errorf("failed to submit job: %w", apiErr), opSubmit)
+
+	var hinted *HintError
+	if !errors.As(err, &hinted) {
+		t.Fatalf("expected HintError, got %T", err)
+	}
+	if !strings.Contains(err.Error(), "check that the job queue exists in this region") {
+		t.Errorf("missing hint: %s", err)
+	}
+	var original smithy.APIError
+	if !errors.As(err, &original) || original.ErrorCode() != "ClientException" {
+		t.Errorf("original API error should stay wrapped, got: %v", err)
+	}
+}
+
+func TestWithHint_Passthrough(t *testing.T) {
+	plain := fmt.Errorf("failed to submit job: %w", errors.New("connection reset"))
+	if err := withHint(plain, opSubmit); err != plain {
+		t.Errorf("non-API errors should be returned unchanged, got: %v", err)
+	}
+
+	unknown := &smithy.GenericAPIError{Code: "SomethingElse"}
+	if err := withHint(unknown, opRegister); err != error(unknown) {
+		t.Errorf("unknown codes should be returned unchanged, got: %v", err)
+	}
+}
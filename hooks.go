@@ -0,0 +1,60 @@
+package batcha
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"time"
+)
+
+// HooksConfig holds shell commands run around registration.
+type HooksConfig struct {
+	// PreRegister runs before any AWS call with the rendered definition as
+	// JSON on stdin. A non-zero exit aborts the registration.
+	PreRegister string `yaml:"pre_register,omitempty" json:"pre_register,omitempty"`
+	// PostRegister runs after a new revision is registered with the result
+	// as JSON on stdin.
+	PostRegister string `yaml:"post_register,omitempty" json:"post_register,omitempty"`
+}
+
+// hookTimeout bounds each hook run (overridden in tests).
+var hookTimeout = 5 * time.Minute
+
+// registerResult is the JSON passed to the post_register hook.
+type registerResult struct {
+	Region            string `json:"region"`
+	JobDefinitionName string `json:"jobDefinitionName"`
+	JobDefinitionArn  string `json:"jobDefinitionArn"`
+	Revision          int32  `json:"revision"`
+}
+
+// runHook runs command with sh -c, writing stdin to it. The hook's output
+// goes to stderr so it does not mix with batcha's own output.
+func (app *App) runHook(parent context.Context, name, command string, stdin []byte) error {
+	ctx, cancel := context.WithTimeout(parent, hookTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "sh", "-c", command)
+	cmd.Stdin = bytes.NewReader(stdin)
+	cmd.Stdout = app.errOut()
+	cmd.Stderr = app.errOut()
+	// Do not wait forever on output pipes inherited by the hook's children.
+	cmd.WaitDelay = time.Second
+	cmd.Env = append(os.Environ(),
+		"BATCHA_HOOK="+name,
+		"BATCHA_CONFIG="+app.configPath,
+		"BATCHA_REGION="+app.config.Region,
+	)
+	if err := cmd.Run(); err != nil {
+		if parent.Err() != nil {
+			return fmt.Errorf("%s hook interrupted: %w", name, parent.Err())
+		}
+		if ctx.Err() == context.DeadlineExceeded {
+			return fmt.Errorf("%s hook timed out after %s: %w", name, hookTimeout, ctx.Err())
+		}
+		return fmt.Errorf("%s hook failed: %w", name, err)
+	}
+	return nil
+}
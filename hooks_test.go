@@ -0,0 +1,102 @@
+package batcha
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+)
+
+// hooksApp returns an app whose config sets the given hooks YAML and whose
+// fake client records registrations.
+func hooksApp(t *testing.T, hooks string, registered *bool) (*App, string) {
+	t.Helper()
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "job.json"), []byte(`{"jobDefinitionName": "hook-job", "type": "container", "containerProperties": {"image": "nginx"}}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := "region: us-east-1\njob_definition: job.json\nhooks:\n" + hooks
+	if err := os.WriteFile(filepath.Join(dir, "batcha.yml"), []byte(cfg), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	app, err := New(context.Background(), filepath.Join(dir, "batcha.yml"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		registerJobDefinition: func(*batch.RegisterJobDefinitionInput) (*batch.RegisterJobDefinitionOutput, error) {
+			*registered = true
+			return &batch.RegisterJobDefinitionOutput{
+				JobDefinitionName: aws.String("hook-job"),
+				JobDefinitionArn:  aws.String("arn:aws:batch:us-east-1:123456789012:job-definition/hook-job:3"),
+				Revision:          aws.Int32(3),
+			}, nil
+		},
+	}}
+	return app, dir
+}
+
+func TestRegister_Hooks(t *testing.T) {
+	var registered bool
+	app, dir := hooksApp(t, `  pre_register: 'grep -q "\"image\": \"nginx\"" && test "$BATCHA_HOOK" = pre_register'
+  post_register: 'cat > "$(dirname "$BATCHA_CONFIG")/post.json"'
+`, &registered)
+
+	var err error
+	captureStdout(t, func() {
+		err = app.Register(context.Background(), RegisterOption{NoSkip: true})
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if !registered {
+		t.Fatal("expected registration after a passing pre_register hook")
+	}
+
+	b, err := os.ReadFile(filepath.Join(dir, "post.json"))
+	if err != nil {
+		t.Fatalf("post_register hook did not run: %v", err)
+	}
+	var res registerResult
+	if err := json.Unmarshal(b, &res); err != nil {
+		t.Fatalf("post_register stdin is not JSON: %v\n%s", err, b)
+	}
+	if res.Revision != 3 || res.Region != "us-east-1" || res.JobDefinitionName != "hook-job" {
+		t.Errorf("unexpected post_register input: %+v", res)
+	}
+}
+
+func TestRegister_PreRegisterHookAborts(t *testing.T) {
+	var registered bool
+	app, _ := hooksApp(t, "  pre_register: 'grep -q privileged'\n", &registered)
+
+	var err error
+	captureStdout(t, func() {
+		err = app.Register(context.Background(), RegisterOption{NoSkip: true})
+	})
+	if err == nil || !strings.Contains(err.Error(), "registration aborted: pre_register hook failed") {
+		t.Errorf("expected the pre_register hook to abort, got: %v", err)
+	}
+	if registered {
+		t.Error("registration must not happen when pre_register fails")
+	}
+}
+
+func TestRunHook_Timeout(t *testing.T) {
+	orig := hookTimeout
+	hookTimeout = 50 * time.Millisecond
+	defer func() { hookTimeout = orig }()
+
+	var registered bool
+	app, _ := hooksApp(t, "  pre_register: 'exec sleep 5'\n", &registered)
+	err := app.runHook(context.Background(), "pre_register", app.config.Hooks.PreRegister, nil)
+	if err == nil || !strings.Contains(err.Error(), "timed out") {
+		t.Errorf("expected a timeout, got: %v", err)
+	}
+}
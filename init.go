@@ -6,10 +6,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"regexp"
+	"strings"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
 	"gopkg.in/yaml.v2"
 )
 
@@ -18,10 +20,33 @@ type InitOption struct {
 	JobDefinitionName string
 	Region            string
 	OutputDir         string
+	// Exclude lists extra fields to strip, as top-level keys or dotted
+	// paths in camelCase (e.g. "tags", "containerProperties.linuxParameters").
+	Exclude []string
+	// Format is the summary format: "text" (default, "Created" lines) or
+	// "json" (an initResult object).
+	Format string
+}
+
+// initResult is the summary printed by init --format json.
+type initResult struct {
+	ConfigPath        string `json:"configPath"`
+	JobDefinitionPath string `json:"jobDefinitionPath"`
+	Region            string `json:"region"`
+	SourceRevision    int32  `json:"sourceRevision"`
 }
 
 // Init fetches an active job definition from AWS and generates config + template files.
 func Init(ctx context.Context, opt InitOption) error {
+	if err := validateExcludePaths(opt.Exclude); err != nil {
+		return err
+	}
+	switch opt.Format {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("unknown format %q (expected text or json)", opt.Format)
+	}
+
 	region := opt.Region
 	if region == "" {
 		region = os.Getenv("AWS_REGION")
@@ -30,12 +55,15 @@ func Init(ctx context.Context, opt InitOption) error {
 		region = os.Getenv("AWS_DEFAULT_REGION")
 	}
 
-	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(region))
+	awsCfg, err := loadAWSConfig(ctx, &Config{Region: region})
 	if err != nil {
 		return fmt.Errorf("failed to load AWS config: %w", err)
 	}
-	client := batch.NewFromConfig(awsCfg)
+	return initFrom(ctx, batch.NewFromConfig(awsCfg), region, opt)
+}
 
+// initFrom generates the files of Init from the definition described by client.
+func initFrom(ctx context.Context, client batchAPI, region string, opt InitOption) error {
 	out, err := client.DescribeJobDefinitions(ctx, &batch.DescribeJobDefinitionsInput{
 		JobDefinitionName: aws.String(opt.JobDefinitionName),
 		Status:            aws.String("ACTIVE"),
@@ -49,24 +77,11 @@ func Init(ctx context.Context, opt InitOption) error {
 
 	latest := pickLatestRevision(out.JobDefinitions)
 
-	// Marshal to JSON then back to map[string]any to get a clean structure
-	jsonBytes, err := json.Marshal(latest)
+	converted, err := initTemplate(latest, opt.Exclude)
 	if err != nil {
-		return fmt.Errorf("failed to marshal job definition: %w", err)
-	}
-	var raw map[string]any
-	if err := json.Unmarshal(jsonBytes, &raw); err != nil {
-		return fmt.Errorf("failed to unmarshal job definition: %w", err)
+		return err
 	}
 
-	// Remove AWS-managed fields that shouldn't be in a template
-	for _, key := range initExcludeKeys {
-		delete(raw, key)
-	}
-
-	// Convert PascalCase to camelCase
-	converted := walkMap(raw, toCamelCase)
-
 	formatted, err := json.MarshalIndent(converted, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to format job definition: %w", err)
@@ -77,7 +92,6 @@ func Init(ctx context.Context, opt InitOption) error {
 	if err := os.WriteFile(jobDefPath, append(formatted, '\n'), 0644); err != nil {
 		return fmt.Errorf("failed to write %s: %w", jobDefPath, err)
 	}
-	fmt.Printf("Created %s\n", jobDefPath)
 
 	// Write batcha.yml
 	cfg := Config{
@@ -92,11 +106,70 @@ func Init(ctx context.Context, opt InitOption) error {
 	if err := os.WriteFile(cfgPath, cfgBytes, 0644); err != nil {
 		return fmt.Errorf("failed to write %s: %w", cfgPath, err)
 	}
+
+	if opt.Format == "json" {
+		return printJSON(initResult{
+			ConfigPath:        cfgPath,
+			JobDefinitionPath: jobDefPath,
+			Region:            region,
+			SourceRevision:    aws.ToInt32(latest.Revision),
+		})
+	}
+	fmt.Printf("Created %s\n", jobDefPath)
 	fmt.Printf("Created %s\n", cfgPath)
+	return nil
+}
 
+// initTemplate converts a remote job definition into template form: AWS-managed
+// fields and the exclude paths are removed and keys are camelCased.
+func initTemplate(def batchTypes.JobDefinition, exclude []string) (map[string]any, error) {
+	// Marshal to JSON then back to map[string]any to get a clean structure
+	jsonBytes, err := json.Marshal(def)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job definition: %w", err)
+	}
+	var raw map[string]any
+	if err := json.Unmarshal(jsonBytes, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal job definition: %w", err)
+	}
+
+	// Remove AWS-managed fields that shouldn't be in a template
+	for _, key := range initExcludeKeys {
+		delete(raw, key)
+	}
+	deleteFingerprintTag(raw)
+
+	// Convert PascalCase to camelCase
+	converted := walkMap(raw, toCamelCase).(map[string]any)
+	for _, path := range exclude {
+		deletePath(converted, strings.Split(path, "."))
+	}
+	return converted, nil
+}
+
+var excludePathPattern = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*(\.[A-Za-z][A-Za-z0-9]*)*$`)
+
+// validateExcludePaths checks that every path is a key or dotted key path.
+func validateExcludePaths(paths []string) error {
+	for _, p := range paths {
+		if !excludePathPattern.MatchString(p) {
+			return fmt.Errorf("invalid --exclude path %q: expected a key or dotted path such as containerProperties.linuxParameters", p)
+		}
+	}
 	return nil
 }
 
+// deletePath removes the value at the key path from m. Missing paths are ignored.
+func deletePath(m map[string]any, keys []string) {
+	if len(keys) == 1 {
+		delete(m, keys[0])
+		return
+	}
+	if child, ok := m[keys[0]].(map[string]any); ok {
+		deletePath(child, keys[1:])
+	}
+}
+
 // initExcludeKeys are fields returned by DescribeJobDefinitions that are
 // AWS-managed and should not be included in a user-managed template.
 var initExcludeKeys = []string{
@@ -0,0 +1,95 @@
+package batcha
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+)
+
+func TestInitTemplate_Exclude(t *testing.T) {
+	def := batchTypes.JobDefinition{
+		JobDefinitionArn:  aws.String("arn:aws:batch:us-east-1:123456789012:job-definition/init-job:2"),
+		JobDefinitionName: aws.String("init-job"),
+		Revision:          aws.Int32(2),
+		Type:              aws.String("container"),
+		Tags:              map[string]string{"team": "data"},
+		ContainerProperties: &batchTypes.ContainerProperties{
+			Image:            aws.String("nginx"),
+			LinuxParameters:  &batchTypes.LinuxParameters{InitProcessEnabled: aws.Bool(true)},
+			ExecutionRoleArn: aws.String("arn:aws:iam::123456789012:role/exec"),
+		},
+	}
+
+	m, err := initTemplate(def, []string{"tags", "containerProperties.linuxParameters"})
+	if err != nil {
+		t.Fatalf("initTemplate failed: %v", err)
+	}
+	if _, ok := m["tags"]; ok {
+		t.Error("expected tags to be excluded")
+	}
+	if _, ok := m["revision"]; ok {
+		t.Error("expected built-in excluded key revision to be removed")
+	}
+	cp := m["containerProperties"].(map[string]any)
+	if _, ok := cp["linuxParameters"]; ok {
+		t.Error("expected containerProperties.linuxParameters to be excluded")
+	}
+	if cp["image"] != "nginx" {
+		t.Errorf("containerProperties.image = %v, want nginx", cp["image"])
+	}
+}
+
+func TestValidateExcludePaths(t *testing.T) {
+	if err := validateExcludePaths([]string{"tags", "containerProperties.linuxParameters"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	for _, bad := range []string{"", "containerProperties..image", "environment[0]", ".tags"} {
+		if err := validateExcludePaths([]string{bad}); err == nil {
+			t.Errorf("expected error for %q", bad)
+		}
+	}
+}
+
+func TestInit_FormatJSON(t *testing.T) {
+	client := &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{JobDefinitions: []batchTypes.JobDefinition{
+				{JobDefinitionName: aws.String("init-job"), Revision: aws.Int32(3), Type: aws.String("container")},
+				{JobDefinitionName: aws.String("init-job"), Revision: aws.Int32(5), Type: aws.String("container")},
+			}}, nil
+		},
+	}
+	dir := t.TempDir()
+
+	var err error
+	out := captureStdout(t, func() {
+		err = initFrom(context.Background(), client, "ap-northeast-1", InitOption{JobDefinitionName: "init-job", OutputDir: dir, Format: "json"})
+	})
+	if err != nil {
+		t.Fatalf("init failed: %v", err)
+	}
+	var got initResult
+	if err := json.Unmarshal([]byte(out), &got); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	want := initResult{
+		ConfigPath:        filepath.Join(dir, "batcha.yml"),
+		JobDefinitionPath: filepath.Join(dir, "job-definition.json"),
+		Region:            "ap-northeast-1",
+		SourceRevision:    5,
+	}
+	if got != want {
+		t.Errorf("result = %+v, want %+v", got, want)
+	}
+	for _, path := range []string{got.ConfigPath, got.JobDefinitionPath} {
+		if _, err := os.Stat(path); err != nil {
+			t.Errorf("expected %s to be written: %v", path, err)
+		}
+	}
+}
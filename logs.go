@@ -2,15 +2,23 @@ package batcha
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
+	"os"
+	"slices"
 	"sort"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
-	awsconfig "github.com/aws/aws-sdk-go-v2/config"
 	"github.com/aws/aws-sdk-go-v2/service/batch"
 	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
 	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	cwlTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/smithy-go"
+	smithyhttp "github.com/aws/smithy-go/transport/http"
 )
 
 // LogsOption holds options for the logs command.
@@ -19,6 +27,39 @@ type LogsOption struct {
 	JobQueue string
 	Follow   bool
 	Since    time.Duration
+	// AutoQueue picks the job queue tagged batcha/default=true when neither
+	// --job-queue nor job_queue is set.
+	AutoQueue bool
+	// Watch follows the latest job and, when it finishes, waits for a newer
+	// job of the definition and follows that one, until interrupted.
+	Watch bool
+
+	// AllRunning tails every RUNNING job of the job definition at once,
+	// with at most Concurrency streams in flight.
+	AllRunning  bool
+	Concurrency int
+
+	// Output is the output format: "text" (default) or "json" (one JSON
+	// object per line).
+	Output string
+	// MaxEvents stops after printing this many events in total, across every
+	// job and stream of the command (0 = unlimited).
+	MaxEvents int
+
+	// SinceLatestSuccess shows events since the most recent SUCCEEDED job of
+	// the job definition stopped.
+	SinceLatestSuccess bool
+
+	// Heartbeat prints "(waiting for logs...)" to stderr after this long
+	// without new events in follow mode (0 = never).
+	Heartbeat time.Duration
+	// FollowTimeout stops following after this long without new events, even
+	// if the job is still running (0 = follow until the job finishes).
+	FollowTimeout time.Duration
+
+	// startTime is the absolute start of the events to show, resolved from
+	// SinceLatestSuccess.
+	startTime time.Time
 }
 
 // Logs fetches and displays CloudWatch logs for a Batch job.
@@ -32,6 +73,19 @@ func (app *App) Logs(ctx context.Context, opt LogsOption) error {
 	if err != nil {
 		return fmt.Errorf("failed to load AWS config: %w", err)
 	}
+	// A given job ID needs no queue.
+	if opt.JobQueue == "" && opt.AutoQueue && opt.JobID == "" {
+		if opt.JobQueue, err = autoJobQueue(ctx, batchClient); err != nil {
+			return err
+		}
+	}
+
+	if opt.AllRunning {
+		return app.logsAllRunning(ctx, batchClient, opt)
+	}
+	if opt.Watch {
+		return app.watchLogs(ctx, batchClient, opt)
+	}
 
 	jobID := opt.JobID
 	if jobID == "" {
@@ -42,6 +96,24 @@ func (app *App) Logs(ctx context.Context, opt LogsOption) error {
 		}
 	}
 
+	if opt.SinceLatestSuccess {
+		success, err := app.findLatestJob(ctx, batchClient, opt.JobQueue, []batchTypes.JobStatus{batchTypes.JobStatusSucceeded})
+		if err != nil {
+			return fmt.Errorf("failed to find the latest successful job: %w", err)
+		}
+		opt.startTime = time.UnixMilli(aws.ToInt64(success.StoppedAt))
+		fmt.Fprintf(os.Stderr, "Showing logs since job %s succeeded at %s\n", aws.ToString(success.JobId), opt.startTime.Format(time.RFC3339))
+	}
+
+	printer, err := newLogPrinter(opt.Output, opt.MaxEvents)
+	if err != nil {
+		return err
+	}
+	return app.logsJob(ctx, batchClient, jobID, opt, printer)
+}
+
+// logsJob prints the header and the log events of a single job.
+func (app *App) logsJob(ctx context.Context, batchClient batchAPI, jobID string, opt LogsOption, printer *logPrinter) error {
 	// Get job details to find log stream
 	descOut, err := batchClient.DescribeJobs(ctx, &batch.DescribeJobsInput{
 		Jobs: []string{jobID},
@@ -59,19 +131,264 @@ func (app *App) Logs(ctx context.Context, opt LogsOption) error {
 		return err
 	}
 
-	fmt.Printf("Job: %s (%s)\n", aws.ToString(job.JobName), aws.ToString(job.JobId))
-	fmt.Printf("Log: %s / %s\n", logGroup, logStream)
-	fmt.Println("---")
+	target := logTarget{
+		jobID:     jobID,
+		jobName:   aws.ToString(job.JobName),
+		logGroup:  logGroup,
+		logStream: logStream,
+	}
+	printer.header(target)
+	printer.separator()
 
-	awsCfg, err := awsconfig.LoadDefaultConfig(ctx, awsconfig.WithRegion(app.config.Region))
+	cwlClient, err := app.newLogsClient(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to load AWS config: %w", err)
 	}
-	cwlClient := cloudwatchlogs.NewFromConfig(awsCfg)
 
+	return app.tailLogStream(ctx, batchClient, cwlClient, target, opt, printer)
+}
+
+// watchLogs follows the latest job of the definition, then keeps waiting for
+// newer jobs and follows each of them in turn. Interrupting it (Ctrl-C) is
+// not an error; an expired --timeout is.
+func (app *App) watchLogs(ctx context.Context, batchClient batchAPI, opt LogsOption) error {
+	opt.Follow = true
+	printer, err := newLogPrinter(opt.Output, opt.MaxEvents)
+	if err != nil {
+		return err
+	}
+
+	var last *batchTypes.JobSummary
+	for {
+		job, err := app.waitForNewerJob(ctx, batchClient, opt.JobQueue, last)
+		if err != nil {
+			return watchErr(ctx, err)
+		}
+		if last != nil {
+			printer.jobSeparator()
+		}
+		last = &job
+		jobID := aws.ToString(job.JobId)
+
+		started, err := waitForLogStream(ctx, batchClient, jobID)
+		if err != nil {
+			return watchErr(ctx, err)
+		}
+		if started {
+			if err := app.logsJob(ctx, batchClient, jobID, opt, printer); err != nil {
+				return watchErr(ctx, err)
+			}
+			if printer.done() {
+				return nil
+			}
+		} else {
+			fmt.Fprintf(os.Stderr, "Job %s finished without a log stream.\n", jobID)
+		}
+		fmt.Fprintf(os.Stderr, "Job %s finished; waiting for a newer job (Ctrl-C to stop)...\n", jobID)
+	}
+}
+
+// watchErr maps an error of watchLogs: nil once ctx is canceled (Ctrl-C),
+// ctx.Err() once its deadline has passed, and err otherwise.
+func watchErr(ctx context.Context, err error) error {
+	switch {
+	case errors.Is(ctx.Err(), context.Canceled):
+		return nil
+	case ctx.Err() != nil:
+		return ctx.Err()
+	}
+	return err
+}
+
+// maxWatchLookupFailures is the number of consecutive failed job lookups
+// after which logs --watch gives up waiting for a newer job.
+const maxWatchLookupFailures = 5
+
+// waitForNewerJob polls until the latest job of the definition was created
+// after last. With a nil last it returns the latest job right away. Only the
+// newest few jobs per status are listed, so a job that is not newer than
+// last (e.g. an older one surfacing after last left the listing) is ignored.
+// Lookup errors are reported on stderr and retried, up to
+// maxWatchLookupFailures in a row.
+func (app *App) waitForNewerJob(ctx context.Context, client batchAPI, jobQueue string, last *batchTypes.JobSummary) (batchTypes.JobSummary, error) {
+	failures := 0
+	for {
+		job, err := app.findLatestJob(ctx, client, jobQueue, allJobStatuses)
+		switch {
+		case err != nil && (last == nil || ctx.Err() != nil):
+			return batchTypes.JobSummary{}, err
+		case err != nil:
+			failures++
+			if failures >= maxWatchLookupFailures {
+				return batchTypes.JobSummary{}, fmt.Errorf("giving up after %d failed attempts to find a newer job: %w", failures, err)
+			}
+			fmt.Fprintf(os.Stderr, "WARNING: failed to find a newer job (attempt %d/%d): %v\n", failures, maxWatchLookupFailures, err)
+		case last == nil || aws.ToInt64(job.CreatedAt) > aws.ToInt64(last.CreatedAt):
+			return job, nil
+		default:
+			failures = 0
+		}
+		select {
+		case <-ctx.Done():
+			return batchTypes.JobSummary{}, ctx.Err()
+		case <-time.After(followPollInterval):
+		}
+	}
+}
+
+// waitForLogStream polls a newly found job until it has a log stream. It
+// returns false when the job finished without one (e.g. it failed to start).
+func waitForLogStream(ctx context.Context, client batchAPI, jobID string) (bool, error) {
+	for {
+		out, err := client.DescribeJobs(ctx, &batch.DescribeJobsInput{Jobs: []string{jobID}})
+		if err != nil {
+			return false, fmt.Errorf("failed to describe job: %w", err)
+		}
+		if len(out.Jobs) == 0 {
+			return false, fmt.Errorf("job %s not found", jobID)
+		}
+		job := out.Jobs[0]
+		if job.Container != nil && aws.ToString(job.Container.LogStreamName) != "" {
+			return true, nil
+		}
+		switch job.Status {
+		case batchTypes.JobStatusSucceeded, batchTypes.JobStatusFailed:
+			return false, nil
+		}
+		select {
+		case <-ctx.Done():
+			return false, ctx.Err()
+		case <-time.After(followPollInterval):
+		}
+	}
+}
+
+// logTarget identifies the CloudWatch log stream of a single job.
+type logTarget struct {
+	jobID     string
+	jobName   string
+	logGroup  string
+	logStream string
+	// prefix is prepended to every printed line (used when tailing many jobs).
+	prefix string
+}
+
+// logPrinter serializes log lines written by concurrent stream tailers.
+// In JSON mode every line is a JSON object whose "type" is "header" or "event".
+// Every printer enforces the --max-events cap for the whole command.
+type logPrinter struct {
+	mu        sync.Mutex
+	json      bool
+	maxEvents int // 0 = unlimited
+	printed   int
+}
+
+func newLogPrinter(output string, maxEvents int) (*logPrinter, error) {
+	switch output {
+	case "", "text":
+		return &logPrinter{maxEvents: maxEvents}, nil
+	case "json":
+		return &logPrinter{json: true, maxEvents: maxEvents}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q (expected text or json)", output)
+}
+
+type logHeaderJSON struct {
+	Type      string `json:"type"`
+	JobID     string `json:"jobId"`
+	JobName   string `json:"jobName"`
+	LogGroup  string `json:"logGroup"`
+	LogStream string `json:"logStream"`
+}
+
+type logEventJSON struct {
+	Type      string `json:"type"`
+	JobID     string `json:"jobId"`
+	Timestamp string `json:"timestamp"`
+	Message   string `json:"message"`
+}
+
+func (p *logPrinter) header(target logTarget) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.json {
+		p.printJSON(logHeaderJSON{
+			Type:      "header",
+			JobID:     target.jobID,
+			JobName:   target.jobName,
+			LogGroup:  target.logGroup,
+			LogStream: target.logStream,
+		})
+		return
+	}
+	fmt.Printf("Job: %s (%s)\n", target.jobName, target.jobID)
+	fmt.Printf("Log: %s / %s\n", target.logGroup, target.logStream)
+}
+
+func (p *logPrinter) separator() {
+	if !p.json {
+		fmt.Println("---")
+	}
+}
+
+// jobSeparator separates jobs in logs --watch. JSON output needs none:
+// every job starts with a header object.
+func (p *logPrinter) jobSeparator() {
+	if !p.json {
+		fmt.Println()
+		fmt.Println("===")
+	}
+}
+
+// print prints event unless the cap is reached and reports whether more
+// events may be printed.
+func (p *logPrinter) print(target logTarget, event cwlTypes.OutputLogEvent) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.capped() {
+		return false
+	}
+	ts := time.UnixMilli(aws.ToInt64(event.Timestamp))
+	if p.json {
+		p.printJSON(logEventJSON{
+			Type:      "event",
+			JobID:     target.jobID,
+			Timestamp: ts.Format(time.RFC3339),
+			Message:   aws.ToString(event.Message),
+		})
+	} else {
+		fmt.Printf("%s%s  %s\n", target.prefix, ts.Format(time.RFC3339), aws.ToString(event.Message))
+	}
+	p.printed++
+	return !p.capped()
+}
+
+// done reports whether the cap has been reached.
+func (p *logPrinter) done() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.capped()
+}
+
+func (p *logPrinter) capped() bool {
+	return p.maxEvents > 0 && p.printed >= p.maxEvents
+}
+
+func (p *logPrinter) printJSON(v any) {
+	b, err := json.Marshal(v)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to marshal log line: %s\n", err)
+		return
+	}
+	fmt.Println(string(b))
+}
+
+// tailLogStream prints the events of a single log stream, following it until
+// the job finishes when opt.Follow is set.
+func (app *App) tailLogStream(ctx context.Context, batchClient batchAPI, cwlClient logsAPI, target logTarget, opt LogsOption, printer *logPrinter) error {
 	input := &cloudwatchlogs.GetLogEventsInput{
-		LogGroupName:  aws.String(logGroup),
-		LogStreamName: aws.String(logStream),
+		LogGroupName:  aws.String(target.logGroup),
+		LogStreamName: aws.String(target.logStream),
 		StartFromHead: aws.Bool(true),
 	}
 	if opt.Since > 0 {
@@ -79,17 +396,42 @@ func (app *App) Logs(ctx context.Context, opt LogsOption) error {
 		input.StartTime = aws.Int64(startTime)
 		input.StartFromHead = aws.Bool(false)
 	}
+	if !opt.startTime.IsZero() {
+		input.StartTime = aws.Int64(opt.startTime.UnixMilli())
+	}
 
 	var prevToken string
+	failures := 0
+	lastEvent, lastHeartbeat := time.Now(), time.Now()
 	for {
+		if printer.done() {
+			return nil // another stream reached the cap
+		}
 		out, err := cwlClient.GetLogEvents(ctx, input)
 		if err != nil {
-			return fmt.Errorf("failed to get log events: %w", err)
+			// Long follow sessions ride out throttling and network blips.
+			if !opt.Follow || ctx.Err() != nil || !isTransientError(err) || failures >= maxFollowRetries {
+				return fmt.Errorf("failed to get log events: %w", err)
+			}
+			delay := followRetryDelay(failures)
+			failures++
+			fmt.Fprintf(os.Stderr, "failed to get log events (retry %d/%d in %s): %s\n", failures, maxFollowRetries, delay, err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			continue
 		}
+		failures = 0
 
 		for _, event := range out.Events {
-			ts := time.UnixMilli(aws.ToInt64(event.Timestamp))
-			fmt.Printf("%s  %s\n", ts.Format(time.RFC3339), aws.ToString(event.Message))
+			if !printer.print(target, event) {
+				return nil
+			}
+		}
+		if len(out.Events) > 0 {
+			lastEvent = time.Now()
 		}
 
 		nextToken := aws.ToString(out.NextForwardToken)
@@ -109,17 +451,31 @@ func (app *App) Logs(ctx context.Context, opt LogsOption) error {
 
 		// In follow mode, wait for new events or job completion
 		if noNewEvents {
-			done, err := app.isJobDone(ctx, batchClient, jobID)
+			done, err := app.isJobDone(ctx, batchClient, target.jobID)
 			if err != nil {
 				return err
 			}
 			if done {
 				break
 			}
+			quiet := time.Since(lastEvent)
+			if opt.FollowTimeout > 0 && quiet >= opt.FollowTimeout {
+				fmt.Fprintf(os.Stderr, "%sNo new log events for %s; stopped following (--follow-timeout)\n", target.prefix, quiet.Round(time.Second))
+				break
+			}
+			if opt.Heartbeat > 0 && quiet >= opt.Heartbeat && time.Since(lastHeartbeat) >= opt.Heartbeat {
+				fmt.Fprintln(os.Stderr, dim(os.Stderr, target.prefix+"(waiting for logs...)"))
+				lastHeartbeat = time.Now()
+			}
 			select {
 			case <-ctx.Done():
+				if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+					next := *input
+					next.NextToken, next.StartTime, next.StartFromHead = out.NextForwardToken, nil, nil
+					flushLogEvents(ctx, cwlClient, &next, target, printer)
+				}
 				return ctx.Err()
-			case <-time.After(2 * time.Second):
+			case <-time.After(followPollInterval):
 			}
 		}
 		prevToken = nextToken
@@ -130,38 +486,223 @@ func (app *App) Logs(ctx context.Context, opt LogsOption) error {
 	return nil
 }
 
-// findLatestJobID finds the most recent job for the configured job definition.
-func (app *App) findLatestJobID(ctx context.Context, client *batch.Client, jobQueue string) (string, error) {
-	if jobQueue == "" {
-		return "", fmt.Errorf("job queue is required to find latest job: set job_queue in config or use --job-queue flag")
+// flushLogEvents prints the events written since the last poll once the
+// --timeout deadline has passed, so a timed-out follow keeps the final lines.
+// It is best effort: errors are ignored.
+func flushLogEvents(ctx context.Context, cwlClient logsAPI, input *cloudwatchlogs.GetLogEventsInput, target logTarget, printer *logPrinter) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), logFlushTimeout)
+	defer cancel()
+	out, err := cwlClient.GetLogEvents(ctx, input)
+	if err != nil {
+		return
+	}
+	for _, event := range out.Events {
+		if !printer.print(target, event) {
+			return
+		}
 	}
+}
+
+// logFlushTimeout bounds the final fetch of flushLogEvents.
+const logFlushTimeout = 5 * time.Second
+
+// followPollInterval is how often follow mode checks for new events.
+var followPollInterval = 2 * time.Second
 
-	rendered, err := app.render(ctx)
+// dim wraps s in the ANSI dim attribute when f is a terminal.
+func dim(f *os.File, s string) string {
+	if fi, err := f.Stat(); err != nil || fi.Mode()&os.ModeCharDevice == 0 {
+		return s
+	}
+	return "\x1b[2m" + s + "\x1b[0m"
+}
+
+// maxFollowRetries is the number of consecutive GetLogEvents failures
+// tolerated in follow mode.
+const maxFollowRetries = 5
+
+// followRetryBaseDelay is the first backoff delay after a failure in follow mode.
+var followRetryBaseDelay = time.Second
+
+// isTransientError reports whether an AWS call may succeed when retried:
+// throttling, a server-side (5xx) failure or a network error. Other API
+// errors such as AccessDeniedException or ResourceNotFoundException are not.
+func isTransientError(err error) bool {
+	var respErr *smithyhttp.ResponseError
+	if errors.As(err, &respErr) && respErr.HTTPStatusCode() >= 500 {
+		return true
+	}
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) {
+		switch apiErr.ErrorCode() {
+		case "ThrottlingException", "TooManyRequestsException", "Throttling", "RequestLimitExceeded",
+			"ServiceUnavailableException", "ServiceUnavailable", "InternalFailure", "InternalServerError":
+			return true
+		}
+		return apiErr.ErrorFault() == smithy.FaultServer
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
+
+// followRetryDelay returns the exponential backoff delay for the given number
+// of previous failures, capped at 30 seconds.
+func followRetryDelay(failures int) time.Duration {
+	return min(followRetryBaseDelay<<failures, 30*time.Second)
+}
+
+// logsAllRunning tails the log streams of every RUNNING job of the configured
+// job definition concurrently, prefixing each line with the job ID.
+func (app *App) logsAllRunning(ctx context.Context, batchClient batchAPI, opt LogsOption) error {
+	if opt.JobQueue == "" {
+		return fmt.Errorf("job queue is required to list running jobs: set job_queue in config or use --job-queue flag")
+	}
+	name, err := app.jobDefinitionName(ctx)
 	if err != nil {
-		return "", err
+		return err
 	}
-	converted := walkMap(rendered, toPascalCase)
-	name, _ := converted.(map[string]any)["JobDefinitionName"].(string)
-	if name == "" {
-		return "", fmt.Errorf("jobDefinitionName is required in job definition")
+
+	jobIDs, err := listRunningJobIDs(ctx, batchClient, opt.JobQueue, name)
+	if err != nil {
+		return err
+	}
+	if len(jobIDs) == 0 {
+		fmt.Printf("No running jobs found for %q in queue %q.\n", name, opt.JobQueue)
+		return nil
 	}
 
+	var jobs []batchTypes.JobDetail
+	for chunk := range slices.Chunk(jobIDs, 100) {
+		descOut, err := batchClient.DescribeJobs(ctx, &batch.DescribeJobsInput{
+			Jobs: chunk,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to describe jobs: %w", err)
+		}
+		jobs = append(jobs, descOut.Jobs...)
+	}
+
+	cwlClient, err := app.newLogsClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+
+	printer, err := newLogPrinter(opt.Output, opt.MaxEvents)
+	if err != nil {
+		return err
+	}
+	var targets []logTarget
+	for _, job := range jobs {
+		jobID := aws.ToString(job.JobId)
+		logGroup, logStream, err := extractLogInfo(job)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "skip %s: %s\n", jobID, err)
+			continue
+		}
+		target := logTarget{
+			jobID:     jobID,
+			jobName:   aws.ToString(job.JobName),
+			logGroup:  logGroup,
+			logStream: logStream,
+			prefix:    "[" + jobID + "] ",
+		}
+		printer.header(target)
+		targets = append(targets, target)
+	}
+	printer.separator()
+
+	concurrency := opt.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultLogsConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+
+	var (
+		wg   sync.WaitGroup
+		mu   sync.Mutex
+		errs []error
+	)
+	for _, target := range targets {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				return
+			}
+			defer func() { <-sem }()
+			if err := app.tailLogStream(ctx, batchClient, cwlClient, target, opt, printer); err != nil {
+				mu.Lock()
+				errs = append(errs, fmt.Errorf("job %s: %w", target.jobID, err))
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	return errors.Join(errs...)
+}
+
+// listRunningJobIDs returns the IDs of RUNNING jobs in the queue that belong
+// to the named job definition.
+func listRunningJobIDs(ctx context.Context, client batchAPI, jobQueue, name string) ([]string, error) {
+	var ids []string
+	paginator := batch.NewListJobsPaginator(client, &batch.ListJobsInput{
+		JobQueue:  aws.String(jobQueue),
+		JobStatus: batchTypes.JobStatusRunning,
+	})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list jobs in queue %q: %w", jobQueue, err)
+		}
+		for _, j := range out.JobSummaryList {
+			if matchesJobDefinition(aws.ToString(j.JobDefinition), name) {
+				ids = append(ids, aws.ToString(j.JobId))
+			}
+		}
+	}
+	return ids, nil
+}
+
+// defaultLogsConcurrency is the number of log streams tailed at once by
+// logs --all-running when --concurrency is not set.
+const defaultLogsConcurrency = 10
+
+// findLatestJobID finds the most recent job for the configured job definition.
+func (app *App) findLatestJobID(ctx context.Context, client batchAPI, jobQueue string) (string, error) {
 	// Search across all statuses to find the most recent job
-	statuses := []batchTypes.JobStatus{
-		batchTypes.JobStatusRunning,
-		batchTypes.JobStatusSucceeded,
-		batchTypes.JobStatusFailed,
-		batchTypes.JobStatusStarting,
-		batchTypes.JobStatusRunnable,
-		batchTypes.JobStatusSubmitted,
-		batchTypes.JobStatusPending,
+	job, err := app.findLatestJob(ctx, client, jobQueue, allJobStatuses)
+	if err != nil {
+		return "", err
 	}
+	return aws.ToString(job.JobId), nil
+}
 
-	type jobEntry struct {
-		id        string
-		createdAt int64
+// allJobStatuses are the statuses searched for the latest job.
+var allJobStatuses = []batchTypes.JobStatus{
+	batchTypes.JobStatusRunning,
+	batchTypes.JobStatusSucceeded,
+	batchTypes.JobStatusFailed,
+	batchTypes.JobStatusStarting,
+	batchTypes.JobStatusRunnable,
+	batchTypes.JobStatusSubmitted,
+	batchTypes.JobStatusPending,
+}
+
+// findLatestJob finds the most recently created job of the configured job
+// definition among jobs in the given statuses.
+func (app *App) findLatestJob(ctx context.Context, client batchAPI, jobQueue string, statuses []batchTypes.JobStatus) (batchTypes.JobSummary, error) {
+	if jobQueue == "" {
+		return batchTypes.JobSummary{}, fmt.Errorf("job queue is required to find latest job: set job_queue in config or use --job-queue flag")
 	}
-	var candidates []jobEntry
+
+	name, err := app.jobDefinitionName(ctx)
+	if err != nil {
+		return batchTypes.JobSummary{}, err
+	}
+
+	var candidates []batchTypes.JobSummary
 	var lastErr error
 
 	for _, status := range statuses {
@@ -176,31 +717,32 @@ func (app *App) findLatestJobID(ctx context.Context, client *batch.Client, jobQu
 		}
 		for _, j := range out.JobSummaryList {
 			if aws.ToString(j.JobName) == name || matchesJobDefinition(aws.ToString(j.JobDefinition), name) {
-				candidates = append(candidates, jobEntry{
-					id:        aws.ToString(j.JobId),
-					createdAt: aws.ToInt64(j.CreatedAt),
-				})
+				candidates = append(candidates, j)
 			}
 		}
 	}
 
 	if len(candidates) == 0 {
 		if lastErr != nil {
-			return "", fmt.Errorf("failed to list jobs in queue %q: %w", jobQueue, lastErr)
+			return batchTypes.JobSummary{}, fmt.Errorf("failed to list jobs in queue %q: %w", jobQueue, lastErr)
+		}
+		if len(statuses) == 1 {
+			return batchTypes.JobSummary{}, fmt.Errorf("no %s jobs found for %q in queue %q", statuses[0], name, jobQueue)
 		}
-		return "", fmt.Errorf("no jobs found for %q in queue %q", name, jobQueue)
+		return batchTypes.JobSummary{}, fmt.Errorf("no jobs found for %q in queue %q", name, jobQueue)
 	}
 
 	// Pick the most recently created job
 	sort.Slice(candidates, func(i, j int) bool {
-		return candidates[i].createdAt > candidates[j].createdAt
+		return aws.ToInt64(candidates[i].CreatedAt) > aws.ToInt64(candidates[j].CreatedAt)
 	})
-	return candidates[0].id, nil
+	return candidates[0], nil
 }
 
 // matchesJobDefinition checks if a job definition ARN matches the given name.
 func matchesJobDefinition(arn, name string) bool {
-	// ARN format: arn:aws:batch:region:account:job-definition/name:revision
+	// ARN format: arn:<partition>:batch:region:account:job-definition/name:revision
+	// (partition is aws, aws-us-gov or aws-cn)
 	// Simple suffix match for the name portion
 	for i := len(arn) - 1; i >= 0; i-- {
 		if arn[i] == '/' {
@@ -238,7 +780,7 @@ func extractLogInfo(job batchTypes.JobDetail) (logGroup, logStream string, err e
 }
 
 // isJobDone checks if the job has reached a terminal state.
-func (app *App) isJobDone(ctx context.Context, client *batch.Client, jobID string) (bool, error) {
+func (app *App) isJobDone(ctx context.Context, client batchAPI, jobID string) (bool, error) {
 	out, err := client.DescribeJobs(ctx, &batch.DescribeJobsInput{
 		Jobs: []string{jobID},
 	})
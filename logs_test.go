@@ -1,10 +1,22 @@
 package batcha
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
 	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	cwlTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/smithy-go"
 )
 
 func TestMatchesJobDefinition(t *testing.T) {
@@ -28,6 +40,16 @@ func TestMatchesJobDefinition(t *testing.T) {
 			name: "test-job",
 			want: true,
 		},
+		{
+			arn:  "arn:aws-us-gov:batch:us-gov-west-1:123456789012:job-definition/gov-job:2",
+			name: "gov-job",
+			want: true,
+		},
+		{
+			arn:  "arn:aws-cn:batch:cn-north-1:123456789012:job-definition/cn-job:5",
+			name: "cn-job",
+			want: true,
+		},
 		{
 			arn:  "my-job",
 			name: "my-job",
@@ -117,3 +139,566 @@ func TestExtractLogInfo(t *testing.T) {
 		}
 	})
 }
+
+type fakeLogsClient struct {
+	getLogEvents func(*cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error)
+}
+
+func (f *fakeLogsClient) GetLogEvents(_ context.Context, in *cloudwatchlogs.GetLogEventsInput, _ ...func(*cloudwatchlogs.Options)) (*cloudwatchlogs.GetLogEventsOutput, error) {
+	return f.getLogEvents(in)
+}
+
+func TestTailLogStream_FollowRecoversFromTransientError(t *testing.T) {
+	followRetryBaseDelay = time.Millisecond
+	t.Cleanup(func() { followRetryBaseDelay = time.Second })
+
+	calls := 0
+	cwlClient := &fakeLogsClient{
+		getLogEvents: func(*cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error) {
+			calls++
+			switch calls {
+			case 1:
+				return nil, &smithy.GenericAPIError{Code: "ThrottlingException", Message: "Rate exceeded"}
+			case 2:
+				return &cloudwatchlogs.GetLogEventsOutput{
+					Events:           []cwlTypes.OutputLogEvent{{Timestamp: aws.Int64(0), Message: aws.String("hello")}},
+					NextForwardToken: aws.String("t1"),
+				}, nil
+			default:
+				return &cloudwatchlogs.GetLogEventsOutput{NextForwardToken: aws.String("t1")}, nil
+			}
+		},
+	}
+	batchClient := &fakeBatchClient{
+		describeJobs: func(*batch.DescribeJobsInput) (*batch.DescribeJobsOutput, error) {
+			return &batch.DescribeJobsOutput{
+				Jobs: []batchTypes.JobDetail{{Status: batchTypes.JobStatusSucceeded}},
+			}, nil
+		},
+	}
+
+	app := &App{config: &Config{}}
+	target := logTarget{jobID: "job-1", logGroup: "/aws/batch/job", logStream: "s"}
+	err := app.tailLogStream(context.Background(), batchClient, cwlClient, target, LogsOption{Follow: true}, &logPrinter{})
+	if err != nil {
+		t.Fatalf("tailLogStream failed: %v", err)
+	}
+	if calls != 3 {
+		t.Errorf("GetLogEvents calls = %d, want 3", calls)
+	}
+}
+
+func TestTailLogStream_FollowFailsFastOnPermanentError(t *testing.T) {
+	followRetryBaseDelay = time.Millisecond
+	t.Cleanup(func() { followRetryBaseDelay = time.Second })
+
+	calls := 0
+	cwlClient := &fakeLogsClient{
+		getLogEvents: func(*cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error) {
+			calls++
+			return nil, &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized"}
+		},
+	}
+	app := &App{config: &Config{}}
+	target := logTarget{jobID: "job-1", logGroup: "/aws/batch/job", logStream: "s"}
+	var err error
+	stderr := captureStderr(t, func() {
+		err = app.tailLogStream(context.Background(), nil, cwlClient, target, LogsOption{Follow: true}, &logPrinter{})
+	})
+	if err == nil || !strings.Contains(err.Error(), "AccessDeniedException") {
+		t.Errorf("expected the access error, got: %v", err)
+	}
+	if calls != 1 || strings.Contains(stderr, "retry") {
+		t.Errorf("GetLogEvents calls = %d, want 1 without retries (stderr: %s)", calls, stderr)
+	}
+}
+
+func TestIsTransientError(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{name: "throttling", err: &smithy.GenericAPIError{Code: "ThrottlingException"}, want: true},
+		{name: "server fault", err: &smithy.GenericAPIError{Code: "SomethingBroke", Fault: smithy.FaultServer}, want: true},
+		{name: "network", err: &net.OpError{Op: "dial", Err: errors.New("connection refused")}, want: true},
+		{name: "not found", err: &smithy.GenericAPIError{Code: "ResourceNotFoundException", Fault: smithy.FaultClient}, want: false},
+		{name: "validation", err: &smithy.GenericAPIError{Code: "InvalidParameterException", Fault: smithy.FaultClient}, want: false},
+		{name: "plain", err: errors.New("boom"), want: false},
+	}
+	for _, tt := range tests {
+		if got := isTransientError(fmt.Errorf("wrapped: %w", tt.err)); got != tt.want {
+			t.Errorf("%s: isTransientError = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestTailLogStream_NoFollowFailsFast(t *testing.T) {
+	cwlClient := &fakeLogsClient{
+		getLogEvents: func(*cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error) {
+			return nil, fmt.Errorf("ThrottlingException: Rate exceeded")
+		},
+	}
+	app := &App{config: &Config{}}
+	target := logTarget{jobID: "job-1", logGroup: "/aws/batch/job", logStream: "s"}
+	if err := app.tailLogStream(context.Background(), nil, cwlClient, target, LogsOption{}, &logPrinter{}); err == nil {
+		t.Fatal("expected error without --follow")
+	}
+}
+
+func TestTailLogStream_FlushOnTimeout(t *testing.T) {
+	followPollInterval = time.Hour
+	t.Cleanup(func() { followPollInterval = 2 * time.Second })
+
+	calls := 0
+	var flushToken string
+	cwlClient := &fakeLogsClient{
+		getLogEvents: func(in *cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error) {
+			if calls++; calls <= 2 {
+				return &cloudwatchlogs.GetLogEventsOutput{NextForwardToken: aws.String("t1")}, nil
+			}
+			flushToken = aws.ToString(in.NextToken)
+			return &cloudwatchlogs.GetLogEventsOutput{
+				Events:           []cwlTypes.OutputLogEvent{{Timestamp: aws.Int64(0), Message: aws.String("last line")}},
+				NextForwardToken: aws.String("t2"),
+			}, nil
+		},
+	}
+	batchClient := &fakeBatchClient{
+		describeJobs: func(*batch.DescribeJobsInput) (*batch.DescribeJobsOutput, error) {
+			return &batch.DescribeJobsOutput{
+				Jobs: []batchTypes.JobDetail{{Status: batchTypes.JobStatusRunning}},
+			}, nil
+		},
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	app := &App{config: &Config{}}
+	target := logTarget{jobID: "job-1", logGroup: "/aws/batch/job", logStream: "s"}
+	var err error
+	out := captureStdout(t, func() {
+		err = app.tailLogStream(ctx, batchClient, cwlClient, target, LogsOption{Follow: true}, &logPrinter{})
+	})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected %v, got: %v", context.DeadlineExceeded, err)
+	}
+	if !strings.Contains(out, "last line") {
+		t.Errorf("expected the events written before the timeout to be flushed, got: %q", out)
+	}
+	if flushToken != "t1" {
+		t.Errorf("flush NextToken = %q, want t1", flushToken)
+	}
+}
+
+func TestLogs_SinceLatestSuccess(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "etl", "type": "container"}`)
+	jobDef := "arn:aws:batch:us-east-1:123456789012:job-definition/etl:1"
+	succeeded := []batchTypes.JobSummary{
+		{JobId: aws.String("ok-old"), JobDefinition: aws.String(jobDef), CreatedAt: aws.Int64(1000), StoppedAt: aws.Int64(2000)},
+		{JobId: aws.String("ok-new"), JobDefinition: aws.String(jobDef), CreatedAt: aws.Int64(3000), StoppedAt: aws.Int64(4000)},
+	}
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		listJobs: func(in *batch.ListJobsInput) (*batch.ListJobsOutput, error) {
+			switch in.JobStatus {
+			case batchTypes.JobStatusSucceeded:
+				return &batch.ListJobsOutput{JobSummaryList: succeeded}, nil
+			case batchTypes.JobStatusFailed:
+				return &batch.ListJobsOutput{JobSummaryList: []batchTypes.JobSummary{
+					{JobId: aws.String("failed"), JobDefinition: aws.String(jobDef), CreatedAt: aws.Int64(5000)},
+				}}, nil
+			}
+			return &batch.ListJobsOutput{}, nil
+		},
+		describeJobs: func(in *batch.DescribeJobsInput) (*batch.DescribeJobsOutput, error) {
+			return &batch.DescribeJobsOutput{Jobs: []batchTypes.JobDetail{{
+				JobId:     aws.String(in.Jobs[0]),
+				Container: &batchTypes.ContainerDetail{LogStreamName: aws.String("etl/default/abc")},
+			}}}, nil
+		},
+	}}
+	var startTime *int64
+	app.logsClient = &fakeLogsClient{
+		getLogEvents: func(in *cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error) {
+			if startTime == nil {
+				startTime = in.StartTime
+			}
+			return &cloudwatchlogs.GetLogEventsOutput{}, nil
+		},
+	}
+
+	if err := app.Logs(context.Background(), LogsOption{JobQueue: "queue", SinceLatestSuccess: true}); err != nil {
+		t.Fatalf("Logs failed: %v", err)
+	}
+	if aws.ToInt64(startTime) != 4000 {
+		t.Errorf("StartTime = %v, want 4000 (stop time of ok-new)", aws.ToInt64(startTime))
+	}
+
+	succeeded = nil
+	err := app.Logs(context.Background(), LogsOption{JobQueue: "queue", SinceLatestSuccess: true})
+	if err == nil || !strings.Contains(err.Error(), "no SUCCEEDED jobs found") {
+		t.Errorf("expected no prior success error, got: %v", err)
+	}
+}
+
+func TestTailLogStream_HeartbeatAndFollowTimeout(t *testing.T) {
+	followPollInterval = time.Millisecond
+	t.Cleanup(func() { followPollInterval = 2 * time.Second })
+
+	cwlClient := &fakeLogsClient{
+		getLogEvents: func(*cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error) {
+			return &cloudwatchlogs.GetLogEventsOutput{NextForwardToken: aws.String("t1")}, nil
+		},
+	}
+	batchClient := &fakeBatchClient{
+		describeJobs: func(*batch.DescribeJobsInput) (*batch.DescribeJobsOutput, error) {
+			return &batch.DescribeJobsOutput{
+				Jobs: []batchTypes.JobDetail{{Status: batchTypes.JobStatusRunning}},
+			}, nil
+		},
+	}
+
+	app := &App{config: &Config{}}
+	target := logTarget{jobID: "job-1", logGroup: "/aws/batch/job", logStream: "s"}
+	opt := LogsOption{Follow: true, Heartbeat: 10 * time.Millisecond, FollowTimeout: 100 * time.Millisecond}
+	var err error
+	stderr := captureStderr(t, func() {
+		err = app.tailLogStream(context.Background(), batchClient, cwlClient, target, opt, &logPrinter{})
+	})
+	if err != nil {
+		t.Fatalf("tailLogStream failed: %v", err)
+	}
+	if n := strings.Count(stderr, "(waiting for logs...)"); n < 2 || n > 10 {
+		t.Errorf("heartbeat printed %d times, want one per interval:\n%s", n, stderr)
+	}
+	if !strings.Contains(stderr, "stopped following (--follow-timeout)") {
+		t.Errorf("expected the follow timeout message:\n%s", stderr)
+	}
+}
+
+func TestTailLogStream_NoHeartbeatByDefault(t *testing.T) {
+	followPollInterval = time.Millisecond
+	t.Cleanup(func() { followPollInterval = 2 * time.Second })
+
+	calls := 0
+	cwlClient := &fakeLogsClient{
+		getLogEvents: func(*cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error) {
+			return &cloudwatchlogs.GetLogEventsOutput{NextForwardToken: aws.String("t1")}, nil
+		},
+	}
+	batchClient := &fakeBatchClient{
+		describeJobs: func(*batch.DescribeJobsInput) (*batch.DescribeJobsOutput, error) {
+			calls++
+			status := batchTypes.JobStatusRunning
+			if calls > 20 {
+				status = batchTypes.JobStatusSucceeded
+			}
+			return &batch.DescribeJobsOutput{Jobs: []batchTypes.JobDetail{{Status: status}}}, nil
+		},
+	}
+
+	app := &App{config: &Config{}}
+	target := logTarget{jobID: "job-1", logGroup: "/aws/batch/job", logStream: "s"}
+	stderr := captureStderr(t, func() {
+		if err := app.tailLogStream(context.Background(), batchClient, cwlClient, target, LogsOption{Follow: true}, &logPrinter{}); err != nil {
+			t.Error(err)
+		}
+	})
+	if stderr != "" {
+		t.Errorf("expected no stderr output without --heartbeat, got:\n%s", stderr)
+	}
+}
+
+func TestLogs_AllRunning(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "dev", "type": "container"}`)
+	arn := func(name string) *string {
+		return aws.String("arn:aws:batch:us-east-1:123456789012:job-definition/" + name + ":1")
+	}
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		listJobs: func(in *batch.ListJobsInput) (*batch.ListJobsOutput, error) {
+			if in.JobStatus != batchTypes.JobStatusRunning {
+				t.Errorf("listed %s jobs, want RUNNING", in.JobStatus)
+			}
+			return &batch.ListJobsOutput{JobSummaryList: []batchTypes.JobSummary{
+				{JobId: aws.String("job-1"), JobDefinition: arn("dev")},
+				{JobId: aws.String("job-2"), JobDefinition: arn("other")},
+				{JobId: aws.String("job-3"), JobDefinition: arn("dev")},
+				{JobId: aws.String("job-4"), JobDefinition: arn("dev-extra")},
+				{JobId: aws.String("job-5"), JobDefinition: arn("dev")},
+				{JobId: aws.String("job-6"), JobDefinition: arn("dev")},
+			}}, nil
+		},
+		describeJobs: func(in *batch.DescribeJobsInput) (*batch.DescribeJobsOutput, error) {
+			var jobs []batchTypes.JobDetail
+			for _, id := range in.Jobs {
+				jobs = append(jobs, batchTypes.JobDetail{
+					JobId:     aws.String(id),
+					JobName:   aws.String("dev"),
+					Status:    batchTypes.JobStatusRunning,
+					Container: &batchTypes.ContainerDetail{LogStreamName: aws.String("dev/default/" + id)},
+				})
+			}
+			return &batch.DescribeJobsOutput{Jobs: jobs}, nil
+		},
+	}}
+	var (
+		mu             sync.Mutex
+		inFlight, peak int
+		streams        []string
+	)
+	app.logsClient = &fakeLogsClient{
+		getLogEvents: func(in *cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error) {
+			mu.Lock()
+			inFlight++
+			peak = max(peak, inFlight)
+			if in.NextToken == nil {
+				streams = append(streams, aws.ToString(in.LogStreamName))
+			}
+			mu.Unlock()
+			time.Sleep(10 * time.Millisecond)
+			mu.Lock()
+			inFlight--
+			mu.Unlock()
+			return &cloudwatchlogs.GetLogEventsOutput{NextForwardToken: aws.String("t")}, nil
+		},
+	}
+
+	var err error
+	captureStdout(t, func() {
+		err = app.Logs(context.Background(), LogsOption{JobQueue: "queue", AllRunning: true, Concurrency: 2})
+	})
+	if err != nil {
+		t.Fatalf("Logs failed: %v", err)
+	}
+	slices.Sort(streams)
+	want := []string{"dev/default/job-1", "dev/default/job-3", "dev/default/job-5", "dev/default/job-6"}
+	if !slices.Equal(streams, want) {
+		t.Errorf("tailed streams = %v, want only the jobs of dev %v", streams, want)
+	}
+	if peak > 2 {
+		t.Errorf("%d streams were tailed at once, want at most 2 (--concurrency)", peak)
+	}
+}
+
+func TestLogs_MaxEventsAcrossJobs(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "dev", "type": "container"}`)
+	jobDef := "arn:aws:batch:us-east-1:123456789012:job-definition/dev:1"
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		listJobs: func(*batch.ListJobsInput) (*batch.ListJobsOutput, error) {
+			return &batch.ListJobsOutput{JobSummaryList: []batchTypes.JobSummary{
+				{JobId: aws.String("job-1"), JobDefinition: aws.String(jobDef)},
+				{JobId: aws.String("job-2"), JobDefinition: aws.String(jobDef)},
+			}}, nil
+		},
+		describeJobs: func(in *batch.DescribeJobsInput) (*batch.DescribeJobsOutput, error) {
+			var jobs []batchTypes.JobDetail
+			for _, id := range in.Jobs {
+				jobs = append(jobs, batchTypes.JobDetail{
+					JobId:     aws.String(id),
+					JobName:   aws.String("dev"),
+					Status:    batchTypes.JobStatusRunning,
+					Container: &batchTypes.ContainerDetail{LogStreamName: aws.String("dev/default/" + id)},
+				})
+			}
+			return &batch.DescribeJobsOutput{Jobs: jobs}, nil
+		},
+	}}
+	app.logsClient = &fakeLogsClient{
+		getLogEvents: func(in *cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error) {
+			if in.NextToken != nil {
+				return &cloudwatchlogs.GetLogEventsOutput{NextForwardToken: in.NextToken}, nil
+			}
+			var events []cwlTypes.OutputLogEvent
+			for range 3 {
+				events = append(events, cwlTypes.OutputLogEvent{Timestamp: aws.Int64(0), Message: aws.String("line")})
+			}
+			return &cloudwatchlogs.GetLogEventsOutput{Events: events, NextForwardToken: aws.String("t")}, nil
+		},
+	}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Logs(context.Background(), LogsOption{JobQueue: "queue", AllRunning: true, MaxEvents: 4})
+	})
+	if err != nil {
+		t.Fatalf("Logs failed: %v", err)
+	}
+	if n := strings.Count(out, "  line\n"); n != 4 {
+		t.Errorf("printed %d events, want 4 in total across both jobs:\n%s", n, out)
+	}
+}
+
+func TestLogs_Watch(t *testing.T) {
+	followPollInterval = time.Millisecond
+	t.Cleanup(func() { followPollInterval = 2 * time.Second })
+
+	app := verifyApp(t, `{"jobDefinitionName": "dev", "type": "container"}`)
+	jobDef := "arn:aws:batch:us-east-1:123456789012:job-definition/dev:1"
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// job-1 has finished; job-2 is submitted later and starts after one poll.
+	var mu sync.Mutex
+	latest, job2Polls := "job-1", 0
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		listJobs: func(in *batch.ListJobsInput) (*batch.ListJobsOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			if in.JobStatus != batchTypes.JobStatusSucceeded {
+				return &batch.ListJobsOutput{}, nil
+			}
+			createdAt := int64(1)
+			if latest == "job-2" {
+				createdAt = 2
+			}
+			return &batch.ListJobsOutput{JobSummaryList: []batchTypes.JobSummary{
+				{JobId: aws.String(latest), JobDefinition: aws.String(jobDef), CreatedAt: aws.Int64(createdAt)},
+			}}, nil
+		},
+		describeJobs: func(in *batch.DescribeJobsInput) (*batch.DescribeJobsOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			id := in.Jobs[0]
+			job := batchTypes.JobDetail{JobId: aws.String(id), JobName: aws.String("dev"), Status: batchTypes.JobStatusSucceeded}
+			if id == "job-2" {
+				if job2Polls++; job2Polls == 1 {
+					job.Status = batchTypes.JobStatusSubmitted
+					return &batch.DescribeJobsOutput{Jobs: []batchTypes.JobDetail{job}}, nil
+				}
+			}
+			job.Container = &batchTypes.ContainerDetail{LogStreamName: aws.String("dev/default/" + id)}
+			return &batch.DescribeJobsOutput{Jobs: []batchTypes.JobDetail{job}}, nil
+		},
+	}}
+	streams := map[string]bool{}
+	app.logsClient = &fakeLogsClient{
+		getLogEvents: func(in *cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error) {
+			mu.Lock()
+			defer mu.Unlock()
+			stream := aws.ToString(in.LogStreamName)
+			if in.NextToken != nil {
+				return &cloudwatchlogs.GetLogEventsOutput{NextForwardToken: in.NextToken}, nil
+			}
+			first := !streams[stream]
+			streams[stream] = true
+			switch {
+			case stream == "dev/default/job-1" && first:
+				latest = "job-2" // resubmitted while job-1 is being followed
+			case stream == "dev/default/job-2" && first:
+				cancel() // Ctrl-C after job-2's logs
+			}
+			return &cloudwatchlogs.GetLogEventsOutput{
+				Events:           []cwlTypes.OutputLogEvent{{Timestamp: aws.Int64(0), Message: aws.String("hello from " + stream)}},
+				NextForwardToken: aws.String("t"),
+			}, nil
+		},
+	}
+
+	var err error
+	out := captureStdout(t, func() {
+		captureStderr(t, func() {
+			err = app.Logs(ctx, LogsOption{JobQueue: "queue", Watch: true})
+		})
+	})
+	if err != nil {
+		t.Fatalf("expected a clean exit on interrupt, got: %v", err)
+	}
+	i1 := strings.Index(out, "hello from dev/default/job-1")
+	sep := strings.Index(out, "\n===\n")
+	i2 := strings.Index(out, "hello from dev/default/job-2")
+	if i1 < 0 || sep < i1 || i2 < sep {
+		t.Errorf("expected job-1 logs, a separator, then job-2 logs:\n%s", out)
+	}
+}
+
+func TestWaitForNewerJob(t *testing.T) {
+	followPollInterval = time.Millisecond
+	t.Cleanup(func() { followPollInterval = 2 * time.Second })
+
+	jobDef := "arn:aws:batch:us-east-1:123456789012:job-definition/dev:1"
+	last := &batchTypes.JobSummary{JobId: aws.String("job-1"), CreatedAt: aws.Int64(10)}
+
+	t.Run("ignores older jobs", func(t *testing.T) {
+		app := verifyApp(t, `{"jobDefinitionName": "dev", "type": "container"}`)
+		lookups := 0
+		app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+			listJobs: func(in *batch.ListJobsInput) (*batch.ListJobsOutput, error) {
+				if in.JobStatus != batchTypes.JobStatusSucceeded {
+					return &batch.ListJobsOutput{}, nil
+				}
+				// job-1 has left the listing; an older job is the latest
+				// listed until job-2 is created.
+				job := batchTypes.JobSummary{JobId: aws.String("job-0"), JobDefinition: aws.String(jobDef), CreatedAt: aws.Int64(5)}
+				if lookups++; lookups > 2 {
+					job = batchTypes.JobSummary{JobId: aws.String("job-2"), JobDefinition: aws.String(jobDef), CreatedAt: aws.Int64(20)}
+				}
+				return &batch.ListJobsOutput{JobSummaryList: []batchTypes.JobSummary{job}}, nil
+			},
+		}}
+		job, err := app.waitForNewerJob(context.Background(), app.batchClients["us-east-1"], "queue", last)
+		if err != nil {
+			t.Fatalf("waitForNewerJob failed: %v", err)
+		}
+		if id := aws.ToString(job.JobId); id != "job-2" {
+			t.Errorf("job = %s, want job-2", id)
+		}
+	})
+
+	t.Run("gives up after repeated failures", func(t *testing.T) {
+		app := verifyApp(t, `{"jobDefinitionName": "dev", "type": "container"}`)
+		app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+			listJobs: func(*batch.ListJobsInput) (*batch.ListJobsOutput, error) {
+				return nil, &smithy.GenericAPIError{Code: "AccessDeniedException"}
+			},
+		}}
+		var err error
+		stderr := captureStderr(t, func() {
+			_, err = app.waitForNewerJob(context.Background(), app.batchClients["us-east-1"], "queue", last)
+		})
+		if err == nil || !strings.Contains(err.Error(), "AccessDeniedException") {
+			t.Errorf("expected the lookup error, got: %v", err)
+		}
+		if n := strings.Count(stderr, "WARNING: failed to find a newer job"); n != maxWatchLookupFailures-1 {
+			t.Errorf("printed %d warnings, want %d:\n%s", n, maxWatchLookupFailures-1, stderr)
+		}
+	})
+}
+
+func TestLogs_WatchStopped(t *testing.T) {
+	followPollInterval = time.Millisecond
+	t.Cleanup(func() { followPollInterval = 2 * time.Second })
+
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+	expired, cancelExpired := context.WithDeadline(context.Background(), time.Now())
+	defer cancelExpired()
+
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		wantErr error
+	}{
+		{name: "interrupt", ctx: canceled},
+		{name: "timeout", ctx: expired, wantErr: context.DeadlineExceeded},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := verifyApp(t, `{"jobDefinitionName": "dev", "type": "container"}`)
+			app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+				listJobs: func(in *batch.ListJobsInput) (*batch.ListJobsOutput, error) {
+					return nil, tt.ctx.Err()
+				},
+			}}
+			var err error
+			captureStderr(t, func() {
+				err = app.Logs(tt.ctx, LogsOption{JobQueue: "queue", Watch: true})
+			})
+			if tt.wantErr == nil && err != nil {
+				t.Errorf("expected a clean exit, got: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Errorf("expected %v, got: %v", tt.wantErr, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,135 @@
+package batcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// PlanOption holds options for the plan command.
+type PlanOption struct {
+	// All plans every region in each config's regions instead of only its
+	// default region.
+	All bool
+	// FingerprintTag predicts register --fingerprint-tag: a latest revision
+	// tagged with the current fingerprint is unchanged.
+	FingerprintTag bool
+}
+
+// Plan actions of a target.
+const (
+	planCreate   = "create"
+	planUpdate   = "update"
+	planNoChange = "no change"
+)
+
+// planTarget is one config in one region.
+type planTarget struct {
+	label string
+	app   *App
+}
+
+// Plan diffs the job definition of every app against AWS and prints one line
+// per target ("<config>: update (2 change(s))") followed by a summary. Every
+// target is attempted and the failures are aggregated. Returns DiffError when
+// any target would be created or updated.
+func Plan(ctx context.Context, apps []*App, opt PlanOption) error {
+	var (
+		failed                    []error
+		create, update, unchanged int
+	)
+	for _, t := range planTargets(apps, opt.All) {
+		action, changes, err := t.app.planAction(ctx, opt)
+		switch {
+		case err != nil:
+			fmt.Printf("%s: error: %s\n", t.label, err)
+			failed = append(failed, fmt.Errorf("%s: %w", t.label, err))
+		case action == planUpdate && changes == 0:
+			update++
+			fmt.Printf("%s: %s (only unset or null fields differ)\n", t.label, action)
+		case action == planUpdate:
+			update++
+			fmt.Printf("%s: %s (%d change(s))\n", t.label, action, changes)
+		default:
+			if action == planCreate {
+				create++
+			} else {
+				unchanged++
+			}
+			fmt.Printf("%s: %s\n", t.label, action)
+		}
+	}
+	fmt.Printf("Plan: %d to create, %d to update, %d unchanged\n", create, update, unchanged)
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to plan %d target(s):\n%w", len(failed), errors.Join(failed...))
+	}
+	if create+update > 0 {
+		return &DiffError{}
+	}
+	return nil
+}
+
+// planTargets lists the targets of apps: each app in its default region, or
+// with all in every region of its config.
+func planTargets(apps []*App, all bool) []planTarget {
+	var targets []planTarget
+	for _, app := range apps {
+		if !all || len(app.config.Regions) <= 1 {
+			targets = append(targets, planTarget{label: app.configPath, app: app})
+			continue
+		}
+		for _, rc := range app.config.Regions {
+			targets = append(targets, planTarget{
+				label: fmt.Sprintf("%s [%s]", app.configPath, rc.Region),
+				app:   app.forRegion(rc.Region),
+			})
+		}
+	}
+	return targets
+}
+
+// planAction reports what register would do in the app's region, using the
+// same no-change checks, and for an update the number of changed paths.
+func (app *App) planAction(ctx context.Context, opt PlanOption) (string, int, error) {
+	rendered, err := app.render(ctx)
+	if err != nil {
+		return "", 0, err
+	}
+	converted := walkMap(rendered, toPascalCase)
+	name, _ := converted.(map[string]any)["JobDefinitionName"].(string)
+	if name == "" {
+		return "", 0, fmt.Errorf("jobDefinitionName is required in job definition")
+	}
+
+	client, err := app.newBatchClient(ctx)
+	if err != nil {
+		return "", 0, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	latest, err := latestActiveDefinition(ctx, client, name)
+	if err != nil {
+		return "", 0, err
+	}
+	if latest == nil {
+		return planCreate, 0, nil
+	}
+	if opt.FingerprintTag {
+		fp, err := fingerprint(rendered)
+		if err != nil {
+			return "", 0, err
+		}
+		if fingerprintMatches(*latest, fp) {
+			return planNoChange, 0, nil
+		}
+	}
+	remoteMap, err := normalizeRemoteDefinition(*latest)
+	if err != nil {
+		return "", 0, err
+	}
+	if sameDefinition(remoteMap, converted) {
+		return planNoChange, 0, nil
+	}
+	// pathDiff treats null as absent, so it can find no change where
+	// register still would register.
+	changes := pathDiff(walkMap(remoteMap, toCamelCase), walkMap(converted, toCamelCase), "")
+	return planUpdate, len(changes), nil
+}
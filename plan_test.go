@@ -0,0 +1,128 @@
+package batcha
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+)
+
+// planClient returns a fake client whose only active definition is def (none
+// when nil).
+func planClient(def *batchTypes.JobDefinition) batchAPI {
+	return &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			out := &batch.DescribeJobDefinitionsOutput{}
+			if def != nil {
+				out.JobDefinitions = []batchTypes.JobDefinition{*def}
+			}
+			return out, nil
+		},
+	}
+}
+
+func TestPlan(t *testing.T) {
+	local := `{"jobDefinitionName": "plan-job", "type": "container", "containerProperties": {"image": "app:v2"}}`
+	staging := verifyApp(t, local)
+	rendered, err := staging.render(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp, err := fingerprint(rendered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	remote := func(image, fp string) *batchTypes.JobDefinition {
+		return &batchTypes.JobDefinition{
+			JobDefinitionName:   aws.String("plan-job"),
+			Revision:            aws.Int32(3),
+			Type:                aws.String("container"),
+			ContainerProperties: &batchTypes.ContainerProperties{Image: aws.String(image)},
+			Tags:                map[string]string{fingerprintTagKey: fp},
+		}
+	}
+
+	staging.batchClients = map[string]batchAPI{"us-east-1": planClient(remote("app:v1", "old"))}
+	prod := verifyApp(t, local)
+	prod.config.Regions = []RegionConfig{{Region: "us-east-1"}, {Region: "us-west-2"}}
+	prod.batchClients = map[string]batchAPI{
+		"us-east-1": planClient(remote("app:v2", fp)),
+		"us-west-2": planClient(nil),
+	}
+	apps := []*App{staging, prod}
+	opt := PlanOption{All: true, FingerprintTag: true}
+
+	out := captureStdout(t, func() { err = Plan(context.Background(), apps, opt) })
+	var diffErr *DiffError
+	if !errors.As(err, &diffErr) {
+		t.Errorf("expected DiffError with pending changes, got: %v", err)
+	}
+	for _, want := range []string{
+		staging.configPath + ": update (1 change(s))",
+		prod.configPath + " [us-east-1]: no change",
+		prod.configPath + " [us-west-2]: create",
+		"Plan: 1 to create, 1 to update, 1 unchanged",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	// Without --all only the default region is planned.
+	out = captureStdout(t, func() { err = Plan(context.Background(), []*App{prod}, PlanOption{FingerprintTag: true}) })
+	if err != nil {
+		t.Errorf("expected no error without pending changes, got: %v", err)
+	}
+	if !strings.Contains(out, prod.configPath+": no change") || strings.Contains(out, "us-west-2") {
+		t.Errorf("expected only the default region, got:\n%s", out)
+	}
+
+	// A failing target is reported and the others are still planned.
+	staging.batchClients["us-east-1"] = &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return nil, errors.New("AccessDeniedException: not authorized")
+		},
+	}
+	out = captureStdout(t, func() { err = Plan(context.Background(), apps, PlanOption{FingerprintTag: true}) })
+	if err == nil || !strings.Contains(err.Error(), "failed to plan 1 target(s)") {
+		t.Errorf("expected an aggregated failure, got: %v", err)
+	}
+	if !strings.Contains(out, staging.configPath+": error: ") || !strings.Contains(out, prod.configPath+": no change") {
+		t.Errorf("unexpected output:\n%s", out)
+	}
+}
+
+func TestPlan_PredictsRegister(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "plan-job", "type": "container", "containerProperties": {"image": "app:v2"}}`)
+	registered := false
+	// The remote matches the template except for fields the SDK reports as
+	// null, which register's comparison does not ignore.
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{JobDefinitions: []batchTypes.JobDefinition{{
+				JobDefinitionName:   aws.String("plan-job"),
+				Revision:            aws.Int32(3),
+				Type:                aws.String("container"),
+				ContainerProperties: &batchTypes.ContainerProperties{Image: aws.String("app:v2")},
+			}}}, nil
+		},
+		registerJobDefinition: func(in *batch.RegisterJobDefinitionInput) (*batch.RegisterJobDefinitionOutput, error) {
+			registered = true
+			return &batch.RegisterJobDefinitionOutput{JobDefinitionName: in.JobDefinitionName, Revision: aws.Int32(4)}, nil
+		},
+	}}
+
+	var err error
+	out := captureStdout(t, func() { err = Plan(context.Background(), []*App{app}, PlanOption{}) })
+	if _, ok := err.(*DiffError); !ok || !strings.Contains(out, ": update (only unset or null fields differ)") {
+		t.Errorf("expected an update, got %v:\n%s", err, out)
+	}
+	captureStdout(t, func() { err = app.Register(context.Background(), RegisterOption{}) })
+	if err != nil || !registered {
+		t.Errorf("expected register to register as plan predicted (%v)", err)
+	}
+}
@@ -0,0 +1,51 @@
+package batcha
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+)
+
+// defaultQueueTag marks the job queue picked by --auto-queue when its value
+// is "true".
+const defaultQueueTag = "batcha/default"
+
+// autoJobQueue returns the only job queue in the region tagged
+// batcha/default=true.
+func autoJobQueue(ctx context.Context, client batchAPI) (string, error) {
+	var tagged []string
+	paginator := batch.NewDescribeJobQueuesPaginator(client, &batch.DescribeJobQueuesInput{})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return "", withHint(fmt.Errorf("failed to describe job queues: %w", err), opDescribe)
+		}
+		for _, q := range out.JobQueues {
+			if q.Tags[defaultQueueTag] == "true" {
+				tagged = append(tagged, aws.ToString(q.JobQueueName))
+			}
+		}
+	}
+	switch len(tagged) {
+	case 0:
+		return "", fmt.Errorf("--auto-queue: no job queue is tagged %s=true", defaultQueueTag)
+	case 1:
+		fmt.Fprintf(os.Stderr, "Using job queue %q (tagged %s=true)\n", tagged[0], defaultQueueTag)
+		return tagged[0], nil
+	}
+	return "", fmt.Errorf("--auto-queue: %d job queues are tagged %s=true (%s); set job_queue or --job-queue", len(tagged), defaultQueueTag, strings.Join(tagged, ", "))
+}
+
+// schedulingPolicyArn returns the fair-share scheduling policy of the job
+// queue, or "" when it has none or cannot be described.
+func schedulingPolicyArn(ctx context.Context, client batchAPI, queue string) string {
+	out, err := client.DescribeJobQueues(ctx, &batch.DescribeJobQueuesInput{JobQueues: []string{queue}})
+	if err != nil || len(out.JobQueues) == 0 {
+		return ""
+	}
+	return aws.ToString(out.JobQueues[0].SchedulingPolicyArn)
+}
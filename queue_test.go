@@ -0,0 +1,126 @@
+package batcha
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+)
+
+// fakeQueues returns a describeJobQueues func serving queues over two pages.
+func fakeQueues(queues ...batchTypes.JobQueueDetail) func(*batch.DescribeJobQueuesInput) (*batch.DescribeJobQueuesOutput, error) {
+	return func(in *batch.DescribeJobQueuesInput) (*batch.DescribeJobQueuesOutput, error) {
+		half := len(queues) / 2
+		if in.NextToken == nil {
+			return &batch.DescribeJobQueuesOutput{JobQueues: queues[:half], NextToken: aws.String("page2")}, nil
+		}
+		return &batch.DescribeJobQueuesOutput{JobQueues: queues[half:]}, nil
+	}
+}
+
+func queue(name string, tags map[string]string) batchTypes.JobQueueDetail {
+	return batchTypes.JobQueueDetail{JobQueueName: aws.String(name), Tags: tags}
+}
+
+func TestAutoJobQueue(t *testing.T) {
+	tests := []struct {
+		name    string
+		queues  []batchTypes.JobQueueDetail
+		want    string
+		wantErr string
+	}{
+		{
+			name: "single tagged queue on the second page",
+			queues: []batchTypes.JobQueueDetail{
+				queue("spot", nil),
+				queue("adhoc", map[string]string{defaultQueueTag: "false"}),
+				queue("main", map[string]string{defaultQueueTag: "true", "team": "data"}),
+			},
+			want: "main",
+		},
+		{
+			name:    "none tagged",
+			queues:  []batchTypes.JobQueueDetail{queue("spot", nil), queue("main", map[string]string{"team": "data"})},
+			wantErr: "no job queue is tagged batcha/default=true",
+		},
+		{
+			name: "several tagged",
+			queues: []batchTypes.JobQueueDetail{
+				queue("spot", map[string]string{defaultQueueTag: "true"}),
+				queue("main", map[string]string{defaultQueueTag: "true"}),
+			},
+			wantErr: "2 job queues are tagged batcha/default=true (spot, main)",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeBatchClient{describeJobQueues: fakeQueues(tt.queues...)}
+			var (
+				got string
+				err error
+			)
+			captureStderr(t, func() {
+				got, err = autoJobQueue(context.Background(), client)
+			})
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Errorf("error = %v, want %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("autoJobQueue() = %q, %v, want %q", got, err, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_AutoQueue(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "auto-job", "type": "container"}`)
+	var submittedQueue string
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobQueues: fakeQueues(queue("spot", nil), queue("main", map[string]string{defaultQueueTag: "true"})),
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{
+				JobDefinitions: []batchTypes.JobDefinition{{JobDefinitionArn: aws.String("arn"), Revision: aws.Int32(1)}},
+			}, nil
+		},
+		submitJob: func(in *batch.SubmitJobInput) (*batch.SubmitJobOutput, error) {
+			submittedQueue = aws.ToString(in.JobQueue)
+			return &batch.SubmitJobOutput{JobId: aws.String("job-1"), JobName: in.JobName}, nil
+		},
+	}}
+
+	if err := app.Run(context.Background(), RunOption{}); err == nil {
+		t.Fatal("expected an error without a job queue or --auto-queue")
+	}
+
+	var err error
+	stderr := captureStderr(t, func() {
+		captureStdout(t, func() {
+			err = app.Run(context.Background(), RunOption{AutoQueue: true})
+		})
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if submittedQueue != "main" {
+		t.Errorf("submitted to %q, want the tagged queue %q", submittedQueue, "main")
+	}
+	if !strings.Contains(stderr, `Using job queue "main"`) {
+		t.Errorf("expected the picked queue on stderr, got %q", stderr)
+	}
+
+	// A configured queue wins without listing queues.
+	app.config.JobQueue = "configured"
+	app.batchClients["us-east-1"].(*fakeBatchClient).describeJobQueues = nil
+	captureStdout(t, func() {
+		err = app.Run(context.Background(), RunOption{AutoQueue: true})
+	})
+	if err != nil || submittedQueue != "configured" {
+		t.Errorf("expected the configured queue to win, got %q (%v)", submittedQueue, err)
+	}
+}
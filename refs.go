@@ -0,0 +1,84 @@
+package batcha
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+// refPattern matches AWS Batch parameter substitution placeholders.
+var refPattern = regexp.MustCompile(`Ref::([A-Za-z0-9_-]+)`)
+
+// noValue is what text/template prints for a field that does not exist.
+const noValue = "<no value>"
+
+type checkRefsKey struct{}
+
+// withCheckRefs returns a context that makes render run checkRefs.
+func withCheckRefs(ctx context.Context) context.Context {
+	return context.WithValue(ctx, checkRefsKey{}, true)
+}
+
+func checkRefsEnabled(ctx context.Context) bool {
+	v, _ := ctx.Value(checkRefsKey{}).(bool)
+	return v
+}
+
+// checkRefs reports template mistakes in the rendered definition: Ref::name
+// placeholders without a default in parameters or defaults, and {{ }}
+// directives that referenced an undefined field and rendered as <no value>.
+func checkRefs(rendered map[string]any, defaults map[string]string) error {
+	params := map[string]bool{}
+	for k := range defaults {
+		params[k] = true
+	}
+	for _, key := range []string{"parameters", "Parameters"} {
+		if m, ok := rendered[key].(map[string]any); ok {
+			for k := range m {
+				params[k] = true
+			}
+		}
+	}
+
+	var errs []error
+	var walk func(path string, v any, refs bool)
+	walk = func(path string, v any, refs bool) {
+		switch val := v.(type) {
+		case map[string]any:
+			keys := make([]string, 0, len(val))
+			for k := range val {
+				keys = append(keys, k)
+			}
+			slices.Sort(keys)
+			for _, k := range keys {
+				p := k
+				if path != "" {
+					p = path + "." + k
+				}
+				// Parameter defaults are values, not placeholders.
+				walk(p, val[k], refs && !(path == "" && (k == "parameters" || k == "Parameters")))
+			}
+		case []any:
+			for i, child := range val {
+				walk(fmt.Sprintf("%s[%d]", path, i), child, refs)
+			}
+		case string:
+			if strings.Contains(val, noValue) {
+				errs = append(errs, fmt.Errorf("%s: a template directive rendered %s (undefined field?)", path, noValue))
+			}
+			if !refs {
+				return
+			}
+			for _, m := range refPattern.FindAllStringSubmatch(val, -1) {
+				if !params[m[1]] {
+					errs = append(errs, fmt.Errorf("%s: Ref::%s has no default in parameters", path, m[1]))
+				}
+			}
+		}
+	}
+	walk("", rendered, true)
+	return errors.Join(errs...)
+}
@@ -0,0 +1,47 @@
+package batcha
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+func TestCheckRefs(t *testing.T) {
+	rendered := map[string]any{
+		"parameters": map[string]any{"input": "Ref::notAPlaceholder"},
+		"containerProperties": map[string]any{
+			"command": []any{"run", "Ref::input", "Ref::output", "Ref::date"},
+			"image":   noValue,
+		},
+	}
+	err := checkRefs(rendered, map[string]string{"date": "today"})
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	msg := err.Error()
+	for _, want := range []string{
+		"containerProperties.command[2]: Ref::output has no default in parameters",
+		"containerProperties.image: a template directive rendered <no value>",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("error %q does not contain %q", msg, want)
+		}
+	}
+	for _, unwanted := range []string{"Ref::input", "Ref::date", "notAPlaceholder"} {
+		if strings.Contains(msg, unwanted) {
+			t.Errorf("error %q should not mention %s", msg, unwanted)
+		}
+	}
+}
+
+func TestRender_CheckRefs(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "job", "type": "container", "containerProperties": {"command": ["echo", "Ref::dangling"]}}`)
+
+	if _, err := app.render(context.Background()); err != nil {
+		t.Fatalf("render without --check-refs failed: %v", err)
+	}
+	_, err := app.render(withCheckRefs(context.Background()))
+	if err == nil || !strings.Contains(err.Error(), "Ref::dangling") {
+		t.Errorf("expected dangling Ref:: error, got %v", err)
+	}
+}
@@ -1,30 +1,165 @@
 package batcha
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"os"
 	"reflect"
+	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
 )
 
 // RegisterOption holds options for the register command.
 type RegisterOption struct {
 	DryRun bool
+	// Validate runs the verify checks on the dry-run payload and fails on
+	// errors. Findings are printed to stderr so stdout stays valid JSON.
+	Validate bool
+	// Explain prints why registration was skipped or performed.
+	Explain bool
+	// DebugGoStruct prints the unmarshaled RegisterJobDefinitionInput.
+	DebugGoStruct bool
+	// NoSkip registers unconditionally without describing the remote
+	// definition for the no-change check.
+	NoSkip bool
+
+	// CheckLimits counts ACTIVE revisions in the account before registering
+	// and warns when the count reaches RevisionThreshold.
+	CheckLimits       bool
+	RevisionThreshold int
+
+	// FromRendered is an already-rendered (camelCase JSON) definition file
+	// registered instead of rendering the template.
+	FromRendered string
+
+	// Concurrency is the number of configs RegisterAll registers at once.
+	Concurrency int
+
+	// NoConvert sends the rendered keys as they are instead of converting
+	// them to PascalCase, for templates already written in PascalCase.
+	NoConvert bool
+
+	// FingerprintTag tags each registered revision with the fingerprint of
+	// the definition (fingerprintTagKey) and skips registration when an
+	// active revision already carries the current fingerprint.
+	FingerprintTag bool
 }
 
-// Register renders and registers the job definition with AWS Batch.
+// fingerprintTagKey is the tag register --fingerprint-tag sets to the
+// definition's fingerprint.
+const fingerprintTagKey = "batcha/fingerprint"
+
+// defaultRegisterConcurrency is the number of configs registered at once
+// with several --config unless --concurrency is set.
+const defaultRegisterConcurrency = 1
+
+// defaultRevisionThreshold is the ACTIVE revision count at which
+// register --check-limits starts warning.
+const defaultRevisionThreshold = 1000
+
+// Register renders and registers the job definition with AWS Batch in every
+// configured region.
 func (app *App) Register(ctx context.Context, opt RegisterOption) error {
+	if opt.Validate && !opt.DryRun {
+		return fmt.Errorf("--validate requires --dry-run")
+	}
+	if opt.FromRendered != "" {
+		def, err := readRenderedFile(opt.FromRendered)
+		if err != nil {
+			return err
+		}
+		app.definition = def
+	}
+	if opt.DryRun {
+		return app.register(ctx, opt)
+	}
+	return app.eachRegion(func(app *App) error {
+		return app.register(ctx, opt)
+	})
+}
+
+// RegisterAll registers the job definition of every app, at most
+// opt.Concurrency at a time. Each app's output is buffered and printed in
+// order once all are done, under a "### <config>" header; its stderr output
+// (hooks and --validate findings) follows on stderr. Every app is attempted
+// and the failures are aggregated.
+func RegisterAll(ctx context.Context, apps []*App, opt RegisterOption) error {
+	if opt.FromRendered != "" {
+		return fmt.Errorf("--from-rendered cannot be combined with several --config")
+	}
+	concurrency := opt.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultRegisterConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+
+	outputs := make([]bytes.Buffer, len(apps))
+	diags := make([]bytes.Buffer, len(apps))
+	errs := make([]error, len(apps))
+	var wg sync.WaitGroup
+	for i, app := range apps {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				errs[i] = ctx.Err()
+				return
+			}
+			defer func() { <-sem }()
+
+			app.stdout, app.stderr = &outputs[i], &diags[i]
+			defer func() { app.stdout, app.stderr = nil, nil }()
+			errs[i] = app.Register(ctx, opt)
+		}()
+	}
+	wg.Wait()
+
+	var failed []error
+	for i, app := range apps {
+		fmt.Printf("### %s\n", app.configPath)
+		fmt.Print(outputs[i].String())
+		fmt.Fprint(os.Stderr, diags[i].String())
+		if errs[i] != nil {
+			fmt.Printf("Error: %s\n", errs[i])
+			failed = append(failed, fmt.Errorf("%s: %w", app.configPath, errs[i]))
+		}
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to register %d of %d config(s):\n%w", len(failed), len(apps), errors.Join(failed...))
+	}
+	return nil
+}
+
+func (app *App) register(ctx context.Context, opt RegisterOption) error {
 	rendered, err := app.render(ctx)
 	if err != nil {
 		return err
 	}
 
-	converted := walkMap(rendered, toPascalCase)
+	converted := convertKeys(rendered, opt.NoConvert)
+
+	// payload is what gets registered; converted stays untagged for the
+	// comparison with the remote, whose fingerprint tag is stripped.
+	payload := converted
+	var fp string
+	if opt.FingerprintTag {
+		if fp, err = fingerprint(rendered); err != nil {
+			return err
+		}
+		payload = withFingerprintTag(converted.(map[string]any), fp)
+	}
 
-	jsonBytes, err := json.Marshal(converted)
+	jsonBytes, err := json.Marshal(payload)
 	if err != nil {
 		return fmt.Errorf("failed to marshal job definition: %w", err)
 	}
@@ -34,7 +169,14 @@ func (app *App) Register(ctx context.Context, opt RegisterOption) error {
 		if err != nil {
 			return fmt.Errorf("failed to format JSON: %w", err)
 		}
-		fmt.Println(string(formatted))
+		fmt.Fprintln(app.out(), string(formatted))
+		if opt.Validate {
+			if opt.NoConvert {
+				// The verify checks expect the template's camelCase keys.
+				rendered = walkMap(rendered, toCamelCase).(map[string]any)
+			}
+			return app.validateDryRun(rendered)
+		}
 		return nil
 	}
 
@@ -42,6 +184,19 @@ func (app *App) Register(ctx context.Context, opt RegisterOption) error {
 	if err := json.Unmarshal(jsonBytes, &input); err != nil {
 		return fmt.Errorf("failed to unmarshal into RegisterJobDefinitionInput: %w", err)
 	}
+	if opt.DebugGoStruct {
+		dumpGoStruct(app.out(), &input)
+	}
+
+	if hook := app.config.Hooks.PreRegister; hook != "" {
+		stdin, err := json.MarshalIndent(rendered, "", "  ")
+		if err != nil {
+			return fmt.Errorf("failed to marshal job definition: %w", err)
+		}
+		if err := app.runHook(ctx, "pre_register", hook, stdin); err != nil {
+			return fmt.Errorf("registration aborted: %w", err)
+		}
+	}
 
 	client, err := app.newBatchClient(ctx)
 	if err != nil {
@@ -50,29 +205,184 @@ func (app *App) Register(ctx context.Context, opt RegisterOption) error {
 
 	// Check if the remote definition already matches
 	name, _ := converted.(map[string]any)["JobDefinitionName"].(string)
-	if name != "" {
+	if name != "" && !opt.NoSkip {
 		out, err := client.DescribeJobDefinitions(ctx, &batch.DescribeJobDefinitionsInput{
 			JobDefinitionName: aws.String(name),
 			Status:            aws.String("ACTIVE"),
 		})
-		if err == nil && len(out.JobDefinitions) > 0 {
+		switch {
+		case err != nil:
+			if opt.Explain {
+				fmt.Fprintf(app.out(), "Explain: could not describe the remote definition (%s); registering.\n", err)
+			}
+		case len(out.JobDefinitions) == 0:
+			if opt.Explain {
+				fmt.Fprintf(app.out(), "Explain: no active revision of %q exists; registering.\n", name)
+			}
+		default:
 			latest := pickLatestRevision(out.JobDefinitions)
+			// Only the latest revision counts: an older one with the same
+			// fingerprint is a rollback target that must be registered again.
+			if fingerprintMatches(latest, fp) {
+				if opt.Explain {
+					fmt.Fprintf(app.out(), "Explain: the latest revision %d is tagged with the current fingerprint %s.\n", aws.ToInt32(latest.Revision), fp)
+				}
+				fmt.Fprintf(app.out(), "No changes detected. Skip registration. (revision %d has the same fingerprint)\n", aws.ToInt32(latest.Revision))
+				return nil
+			}
 			remoteMap, err := normalizeRemoteDefinition(latest)
-			if err == nil && reflect.DeepEqual(remoteMap, converted) {
-				fmt.Printf("No changes detected. Skip registration. (current revision: %d)\n", aws.ToInt32(latest.Revision))
+			if err == nil && sameDefinition(remoteMap, converted) {
+				if opt.Explain {
+					fmt.Fprintf(app.out(), "Explain: the normalized remote revision %d is identical to the local definition.\n", aws.ToInt32(latest.Revision))
+				}
+				fmt.Fprintf(app.out(), "No changes detected. Skip registration. (current revision: %d)\n", aws.ToInt32(latest.Revision))
 				return nil
 			}
+			if opt.Explain && err == nil {
+				diff, err := definitionDiff(remoteMap, converted, DiffOption{LabelA: "remote", LabelB: "local"})
+				if err != nil {
+					return err
+				}
+				fmt.Fprintf(app.out(), "Explain: the local definition differs from remote revision %d:\n%s\n", aws.ToInt32(latest.Revision), diff)
+			}
+		}
+	}
+
+	if opt.CheckLimits {
+		if err := checkRevisionLimit(ctx, app.out(), client, name, opt.RevisionThreshold); err != nil {
+			return err
 		}
 	}
 
 	result, err := client.RegisterJobDefinition(ctx, &input)
 	if err != nil {
-		return fmt.Errorf("failed to register job definition: %w", err)
+		return withHint(fmt.Errorf("failed to register job definition: %w", err), opRegister)
 	}
 
-	fmt.Printf("Registered: %s revision %d\n",
+	fmt.Fprintf(app.out(), "Registered: %s revision %d\n",
 		aws.ToString(result.JobDefinitionName),
 		aws.ToInt32(result.Revision),
 	)
+
+	if hook := app.config.Hooks.PostRegister; hook != "" {
+		stdin, err := json.Marshal(registerResult{
+			Region:            app.config.Region,
+			JobDefinitionName: aws.ToString(result.JobDefinitionName),
+			JobDefinitionArn:  aws.ToString(result.JobDefinitionArn),
+			Revision:          aws.ToInt32(result.Revision),
+		})
+		if err != nil {
+			return fmt.Errorf("failed to marshal register result: %w", err)
+		}
+		if err := app.runHook(ctx, "post_register", hook, stdin); err != nil {
+			return fmt.Errorf("job definition was registered but %w", err)
+		}
+	}
 	return nil
 }
+
+// withFingerprintTag returns a copy of the PascalCase definition def with the
+// fingerprint tag added to its tags.
+func withFingerprintTag(def map[string]any, fp string) map[string]any {
+	tags := map[string]any{}
+	if t, ok := def["Tags"].(map[string]any); ok {
+		maps.Copy(tags, t)
+	}
+	tags[fingerprintTagKey] = fp
+	def = maps.Clone(def)
+	def["Tags"] = tags
+	return def
+}
+
+// fingerprintMatches reports whether def is tagged with fingerprint fp. It
+// never matches an empty fp.
+func fingerprintMatches(def batchTypes.JobDefinition, fp string) bool {
+	return fp != "" && def.Tags[fingerprintTagKey] == fp
+}
+
+// sameDefinition is the no-change check of register: the normalized remote
+// definition equals the local one exactly. plan uses it to predict register.
+func sameDefinition(remoteMap map[string]any, converted any) bool {
+	return reflect.DeepEqual(remoteMap, converted)
+}
+
+// readRenderedFile reads a job definition written by render.
+func readRenderedFile(path string) (map[string]any, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rendered definition: %w", err)
+	}
+	var def map[string]any
+	if err := json.Unmarshal(b, &def); err != nil {
+		return nil, fmt.Errorf("failed to parse rendered definition %s: %w", path, err)
+	}
+	if len(def) == 0 {
+		return nil, fmt.Errorf("rendered definition %s is empty", path)
+	}
+	return def, nil
+}
+
+// convertKeys converts the rendered template to the PascalCase keys of the
+// AWS SDK, or returns it unchanged with noConvert.
+func convertKeys(rendered map[string]any, noConvert bool) any {
+	if noConvert {
+		return rendered
+	}
+	return walkMap(rendered, toPascalCase)
+}
+
+// validateDryRun reports the verify findings of a dry-run payload on stderr.
+func (app *App) validateDryRun(rendered map[string]any) error {
+	res, err := app.checkRendered(rendered)
+	if err != nil {
+		return err
+	}
+	for _, w := range res.warns {
+		fmt.Fprintf(app.errOut(), "WARN: %s\n", w)
+	}
+	for _, e := range res.errs {
+		fmt.Fprintf(app.errOut(), "NG: %s\n", e)
+	}
+	if len(res.errs) > 0 {
+		return fmt.Errorf("validation failed with %d error(s)", len(res.errs))
+	}
+	return nil
+}
+
+// checkRevisionLimit counts ACTIVE job definition revisions across the account
+// and warns on w when the total reaches threshold.
+func checkRevisionLimit(ctx context.Context, w io.Writer, client batchAPI, name string, threshold int) error {
+	if threshold <= 0 {
+		threshold = defaultRevisionThreshold
+	}
+	total, own, err := countActiveRevisions(ctx, client, name)
+	if err != nil {
+		return err
+	}
+	if total >= threshold {
+		fmt.Fprintf(w, "WARN: %d ACTIVE job definition revisions in this account and region (threshold %d, %d of them for %q). Consider deregistering old revisions.\n",
+			total, threshold, own, name)
+	}
+	return nil
+}
+
+// countActiveRevisions returns the number of ACTIVE job definition revisions
+// in the account and how many of them belong to name.
+func countActiveRevisions(ctx context.Context, client batchAPI, name string) (total, own int, err error) {
+	paginator := batch.NewDescribeJobDefinitionsPaginator(client, &batch.DescribeJobDefinitionsInput{
+		Status: aws.String("ACTIVE"),
+	})
+	for paginator.HasMorePages() {
+		out, err := paginator.NextPage(ctx)
+		if err != nil {
+			return 0, 0, fmt.Errorf("failed to describe job definitions: %w", err)
+		}
+		for _, d := range out.JobDefinitions {
+			total++
+			if aws.ToString(d.JobDefinitionName) == name {
+				own++
+			}
+		}
+	}
+	return total, own, nil
+}
@@ -2,8 +2,20 @@ package batcha
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
 )
 
 func TestRegister_DryRun(t *testing.T) {
@@ -20,3 +32,450 @@ func TestRegister_DryRun(t *testing.T) {
 		t.Fatalf("Register dry-run failed: %v", err)
 	}
 }
+
+func TestRegister_DryRunValidate(t *testing.T) {
+	app := verifyApp(t, `{
+  "jobDefinitionName": "validate-job",
+  "type": "container",
+  "containerProperties": {"image": "nginx"}
+}`)
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Register(context.Background(), RegisterOption{DryRun: true, Validate: true})
+	})
+	if err == nil || !strings.Contains(err.Error(), "validation failed") {
+		t.Errorf("expected validation failure for missing resourceRequirements, got: %v", err)
+	}
+	if !strings.Contains(out, `"JobDefinitionName": "validate-job"`) {
+		t.Errorf("expected the payload on stdout, got:\n%s", out)
+	}
+
+	if err := app.Register(context.Background(), RegisterOption{Validate: true}); err == nil {
+		t.Error("expected --validate without --dry-run to fail")
+	}
+}
+
+func TestCountActiveRevisions(t *testing.T) {
+	// Two pages: 3 revisions of "my-job" and 2 of "other-job".
+	pages := map[string]*batch.DescribeJobDefinitionsOutput{
+		"": {
+			JobDefinitions: []batchTypes.JobDefinition{
+				{JobDefinitionName: aws.String("my-job"), Revision: aws.Int32(1)},
+				{JobDefinitionName: aws.String("my-job"), Revision: aws.Int32(2)},
+				{JobDefinitionName: aws.String("other-job"), Revision: aws.Int32(1)},
+			},
+			NextToken: aws.String("page2"),
+		},
+		"page2": {
+			JobDefinitions: []batchTypes.JobDefinition{
+				{JobDefinitionName: aws.String("my-job"), Revision: aws.Int32(3)},
+				{JobDefinitionName: aws.String("other-job"), Revision: aws.Int32(2)},
+			},
+		},
+	}
+	client := &fakeBatchClient{
+		describeJobDefinitions: func(in *batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			if aws.ToString(in.Status) != "ACTIVE" {
+				t.Errorf("Status = %q, want ACTIVE", aws.ToString(in.Status))
+			}
+			return pages[aws.ToString(in.NextToken)], nil
+		},
+	}
+
+	total, own, err := countActiveRevisions(context.Background(), client, "my-job")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if total != 5 || own != 3 {
+		t.Errorf("countActiveRevisions = %d, %d, want 5, 3", total, own)
+	}
+
+	if err := checkRevisionLimit(context.Background(), os.Stdout, client, "my-job", 5); err != nil {
+		t.Errorf("checkRevisionLimit failed: %v", err)
+	}
+}
+
+func TestRegister_Explain(t *testing.T) {
+	app := verifyApp(t, `{
+  "jobDefinitionName": "same-job",
+  "type": "container",
+  "containerProperties": {"image": "nginx"}
+}`)
+	registered := false
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{
+				JobDefinitions: []batchTypes.JobDefinition{{
+					JobDefinitionArn:    aws.String("arn:aws:batch:us-east-1:123456789012:job-definition/same-job:4"),
+					JobDefinitionName:   aws.String("same-job"),
+					Revision:            aws.Int32(4),
+					Status:              aws.String("ACTIVE"),
+					Type:                aws.String("container"),
+					ContainerProperties: &batchTypes.ContainerProperties{Image: aws.String("nginx:old")},
+				}},
+			}, nil
+		},
+		registerJobDefinition: func(*batch.RegisterJobDefinitionInput) (*batch.RegisterJobDefinitionOutput, error) {
+			registered = true
+			return &batch.RegisterJobDefinitionOutput{}, nil
+		},
+	}}
+
+	if err := app.Register(context.Background(), RegisterOption{Explain: true}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if !registered {
+		t.Error("expected a changed definition to be registered")
+	}
+}
+
+func TestRegister_NoSkip(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "no-skip-job", "type": "container"}`)
+	registered := false
+	// describeJobDefinitions is left nil: calling it would panic.
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		registerJobDefinition: func(*batch.RegisterJobDefinitionInput) (*batch.RegisterJobDefinitionOutput, error) {
+			registered = true
+			return &batch.RegisterJobDefinitionOutput{}, nil
+		},
+	}}
+
+	if err := app.Register(context.Background(), RegisterOption{NoSkip: true}); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if !registered {
+		t.Error("expected registration with --no-skip")
+	}
+}
+
+func TestRegister_FromRendered(t *testing.T) {
+	// The template would fail to render; --from-rendered must not touch it.
+	app := verifyApp(t, `{"jobDefinitionName": "{{ must_env "UNSET_FOR_TEST" }}"}`)
+	rendered := filepath.Join(t.TempDir(), "rendered.json")
+	if err := os.WriteFile(rendered, []byte(`{
+  "jobDefinitionName": "rendered-job",
+  "type": "container",
+  "containerProperties": {"image": "nginx", "jobRoleArn": "arn:aws:iam::123456789012:role/job"}
+}`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Register(context.Background(), RegisterOption{DryRun: true, FromRendered: rendered})
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	for _, want := range []string{`"JobDefinitionName": "rendered-job"`, `"JobRoleArn": "arn:aws:iam::123456789012:role/job"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("dry-run output missing %q:\n%s", want, out)
+		}
+	}
+
+	if err := app.Register(context.Background(), RegisterOption{DryRun: true, FromRendered: filepath.Join(t.TempDir(), "missing.json")}); err == nil {
+		t.Error("expected an error for a missing rendered file")
+	}
+}
+
+func TestRegisterAll(t *testing.T) {
+	var (
+		mu          sync.Mutex
+		inFlight    int
+		maxInFlight int
+	)
+	// Earlier configs take longer, so they finish out of order.
+	delays := map[string]time.Duration{"job-a": 60 * time.Millisecond, "job-b": 30 * time.Millisecond, "job-c": 0}
+	client := &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{}, nil
+		},
+		registerJobDefinition: func(in *batch.RegisterJobDefinitionInput) (*batch.RegisterJobDefinitionOutput, error) {
+			mu.Lock()
+			inFlight++
+			maxInFlight = max(maxInFlight, inFlight)
+			mu.Unlock()
+			defer func() {
+				mu.Lock()
+				inFlight--
+				mu.Unlock()
+			}()
+
+			name := aws.ToString(in.JobDefinitionName)
+			time.Sleep(delays[name])
+			if name == "job-b" {
+				return nil, errors.New("ClientException: invalid definition")
+			}
+			return &batch.RegisterJobDefinitionOutput{JobDefinitionName: in.JobDefinitionName, Revision: aws.Int32(1)}, nil
+		},
+	}
+	var apps []*App
+	for _, name := range []string{"job-a", "job-b", "job-c"} {
+		app := verifyApp(t, `{"jobDefinitionName": "`+name+`", "type": "container", "containerProperties": {"image": "nginx"}}`)
+		app.batchClients = map[string]batchAPI{"us-east-1": client}
+		apps = append(apps, app)
+	}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = RegisterAll(context.Background(), apps, RegisterOption{Concurrency: 2})
+	})
+	if err == nil || !strings.Contains(err.Error(), "failed to register 1 of 3 config(s)") || !strings.Contains(err.Error(), "invalid definition") {
+		t.Errorf("expected an aggregated failure for job-b, got: %v", err)
+	}
+	if maxInFlight != 2 {
+		t.Errorf("max concurrent registrations = %d, want 2", maxInFlight)
+	}
+
+	var positions []int
+	for _, want := range []string{
+		"### " + apps[0].configPath, "Registered: job-a revision 1",
+		"### " + apps[1].configPath, "Error: failed to register job definition",
+		"### " + apps[2].configPath, "Registered: job-c revision 1",
+	} {
+		i := strings.Index(out, want)
+		if i < 0 {
+			t.Fatalf("output missing %q:\n%s", want, out)
+		}
+		positions = append(positions, i)
+	}
+	if !slices.IsSorted(positions) {
+		t.Errorf("output is not in config order:\n%s", out)
+	}
+
+	registered := 0
+	client.registerJobDefinition = func(*batch.RegisterJobDefinitionInput) (*batch.RegisterJobDefinitionOutput, error) {
+		registered++
+		return nil, errors.New("unexpected call")
+	}
+	out = captureStdout(t, func() {
+		err = RegisterAll(context.Background(), apps, RegisterOption{DryRun: true, Concurrency: 3})
+	})
+	if err != nil || registered != 0 {
+		t.Errorf("dry run must not register, got %d call(s) (%v)", registered, err)
+	}
+	if a, c := strings.Index(out, `"JobDefinitionName": "job-a"`), strings.Index(out, `"JobDefinitionName": "job-c"`); a < 0 || c < a {
+		t.Errorf("expected the rendered definitions in config order:\n%s", out)
+	}
+}
+
+func TestRegister_NoConvert(t *testing.T) {
+	app := verifyApp(t, `{
+  "JobDefinitionName": "pascal-job",
+  "Type": "container",
+  "ContainerProperties": {
+    "Image": "nginx",
+    "ResourceRequirements": [{"Type": "VCPU", "Value": "1"}, {"Type": "MEMORY", "Value": "2048"}]
+  },
+  "Tags": {"team": "data"}
+}`)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Register(context.Background(), RegisterOption{DryRun: true, Validate: true, NoConvert: true})
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	for _, want := range []string{`"JobDefinitionName": "pascal-job"`, `"ResourceRequirements"`, `"team": "data"`} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+
+	// Keys are not converted at all, so a camelCase key stays as written.
+	app = verifyApp(t, `{"jobDefinitionName": "camel-job", "type": "container"}`)
+	out = captureStdout(t, func() {
+		err = app.Register(context.Background(), RegisterOption{DryRun: true, NoConvert: true})
+	})
+	if err != nil || !strings.Contains(out, `"jobDefinitionName": "camel-job"`) {
+		t.Errorf("expected the keys unchanged, got (%v):\n%s", err, out)
+	}
+}
+
+func TestRegister_FingerprintTag(t *testing.T) {
+	jobDef := `{"jobDefinitionName": "fp-job", "type": "container", "containerProperties": {"image": "app:v1"}, "tags": {"team": "data"}}`
+	app := verifyApp(t, jobDef)
+	rendered, err := app.render(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fp, err := fingerprint(rendered)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The remote differs only by a server-side default, which DeepEqual
+	// would treat as a change.
+	active := []batchTypes.JobDefinition{{
+		JobDefinitionName:    aws.String("fp-job"),
+		Revision:             aws.Int32(4),
+		Type:                 aws.String("container"),
+		PlatformCapabilities: []batchTypes.PlatformCapability{batchTypes.PlatformCapabilityEc2},
+		ContainerProperties:  &batchTypes.ContainerProperties{Image: aws.String("app:v1")},
+		Tags:                 map[string]string{"team": "data", fingerprintTagKey: fp},
+	}}
+	var registered *batch.RegisterJobDefinitionInput
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{JobDefinitions: active}, nil
+		},
+		registerJobDefinition: func(in *batch.RegisterJobDefinitionInput) (*batch.RegisterJobDefinitionOutput, error) {
+			registered = in
+			return &batch.RegisterJobDefinitionOutput{JobDefinitionName: in.JobDefinitionName, Revision: aws.Int32(5)}, nil
+		},
+	}}
+
+	out := captureStdout(t, func() {
+		err = app.Register(context.Background(), RegisterOption{FingerprintTag: true})
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if registered != nil || !strings.Contains(out, "Skip registration. (revision 4 has the same fingerprint)") {
+		t.Errorf("expected a skip on the matching fingerprint, got:\n%s", out)
+	}
+
+	// Another fingerprint registers, tagging the new revision.
+	active[0].Tags[fingerprintTagKey] = "0000"
+	captureStdout(t, func() {
+		err = app.Register(context.Background(), RegisterOption{FingerprintTag: true})
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if registered == nil {
+		t.Fatal("expected a registration")
+	}
+	if want := map[string]string{"team": "data", fingerprintTagKey: fp}; !maps.Equal(registered.Tags, want) {
+		t.Errorf("Tags = %v, want %v", registered.Tags, want)
+	}
+}
+
+func TestRegisterAll_Stderr(t *testing.T) {
+	client := &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{}, nil
+		},
+		registerJobDefinition: func(in *batch.RegisterJobDefinitionInput) (*batch.RegisterJobDefinitionOutput, error) {
+			return &batch.RegisterJobDefinitionOutput{JobDefinitionName: in.JobDefinitionName, Revision: aws.Int32(1)}, nil
+		},
+	}
+	var apps []*App
+	// The first hook finishes last, so unbuffered output would be out of order.
+	for i, delay := range []string{"0.2", "0.1", "0"} {
+		app := verifyApp(t, fmt.Sprintf(`{"jobDefinitionName": "job-%d", "type": "container", "containerProperties": {"image": "nginx"}}`, i))
+		app.batchClients = map[string]batchAPI{"us-east-1": client}
+		app.config.Hooks.PreRegister = fmt.Sprintf(`sleep %s; echo "pre_register job-%d"`, delay, i)
+		apps = append(apps, app)
+	}
+
+	var err error
+	stderr := captureStderr(t, func() {
+		captureStdout(t, func() {
+			err = RegisterAll(context.Background(), apps, RegisterOption{Concurrency: 3})
+		})
+	})
+	if err != nil {
+		t.Fatalf("RegisterAll failed: %v", err)
+	}
+	if want := "pre_register job-0\npre_register job-1\npre_register job-2\n"; stderr != want {
+		t.Errorf("stderr = %q, want the hook output in config order %q", stderr, want)
+	}
+}
+
+func TestRegister_FingerprintTagRollback(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "fp-job", "type": "container", "containerProperties": {"image": "app:v1"}}`)
+	rendered, err := app.render(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fpA, err := fingerprint(rendered)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Revision 5 has the template's content A, revision 6 (the latest) B.
+	active := []batchTypes.JobDefinition{
+		{
+			JobDefinitionName:   aws.String("fp-job"),
+			Revision:            aws.Int32(5),
+			Type:                aws.String("container"),
+			ContainerProperties: &batchTypes.ContainerProperties{Image: aws.String("app:v1")},
+			Tags:                map[string]string{fingerprintTagKey: fpA},
+		},
+		{
+			JobDefinitionName:   aws.String("fp-job"),
+			Revision:            aws.Int32(6),
+			Type:                aws.String("container"),
+			ContainerProperties: &batchTypes.ContainerProperties{Image: aws.String("app:v2")},
+			Tags:                map[string]string{fingerprintTagKey: "b"},
+		},
+	}
+	var registered *batch.RegisterJobDefinitionInput
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{JobDefinitions: active}, nil
+		},
+		registerJobDefinition: func(in *batch.RegisterJobDefinitionInput) (*batch.RegisterJobDefinitionOutput, error) {
+			registered = in
+			return &batch.RegisterJobDefinitionOutput{JobDefinitionName: in.JobDefinitionName, Revision: aws.Int32(7)}, nil
+		},
+	}}
+
+	out := captureStdout(t, func() {
+		err = app.Register(context.Background(), RegisterOption{FingerprintTag: true})
+	})
+	if err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+	if registered == nil || !strings.Contains(out, "Registered: fp-job revision 7") {
+		t.Fatalf("expected the rollback to A to be registered, got:\n%s", out)
+	}
+	if registered.Tags[fingerprintTagKey] != fpA {
+		t.Errorf("Tags = %v, want fingerprint %s", registered.Tags, fpA)
+	}
+}
+
+func TestRegister_FingerprintTagDiffsClean(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "fp-job", "type": "container", "containerProperties": {"image": "app:v1"}}`)
+	var active []batchTypes.JobDefinition
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{JobDefinitions: active}, nil
+		},
+		registerJobDefinition: func(in *batch.RegisterJobDefinitionInput) (*batch.RegisterJobDefinitionOutput, error) {
+			// The registered revision is what AWS describes afterwards.
+			active = []batchTypes.JobDefinition{{
+				JobDefinitionName:   in.JobDefinitionName,
+				Revision:            aws.Int32(1),
+				Type:                aws.String(string(in.Type)),
+				ContainerProperties: in.ContainerProperties,
+				Tags:                in.Tags,
+			}}
+			return &batch.RegisterJobDefinitionOutput{JobDefinitionName: in.JobDefinitionName, Revision: aws.Int32(1)}, nil
+		},
+	}}
+
+	var err error
+	captureStdout(t, func() {
+		err = app.Register(context.Background(), RegisterOption{FingerprintTag: true})
+	})
+	if err != nil || len(active) != 1 || active[0].Tags[fingerprintTagKey] == "" {
+		t.Fatalf("expected a fingerprinted revision, got %v (%v)", active, err)
+	}
+
+	stderr := captureStderr(t, func() {
+		out := captureStdout(t, func() { err = app.Diff(context.Background(), DiffOption{Compact: true}) })
+		if err != nil || !strings.Contains(out, "No differences found.") {
+			t.Errorf("expected a clean diff, got %v:\n%s", err, out)
+		}
+		out = captureStdout(t, func() { err = Plan(context.Background(), []*App{app}, PlanOption{FingerprintTag: true}) })
+		if err != nil || !strings.Contains(out, ": no change") {
+			t.Errorf("expected plan to report no change, got %v:\n%s", err, out)
+		}
+	})
+	if strings.Contains(stderr, "WARNING") {
+		t.Errorf("the fingerprint tag must not be reported as drift:\n%s", stderr)
+	}
+}
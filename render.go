@@ -2,22 +2,71 @@ package batcha
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"net/url"
+	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"text/template"
 
+	"github.com/aws/aws-sdk-go-v2/service/batch"
 	goconfig "github.com/kayac/go-config"
+	"gopkg.in/yaml.v2"
 )
 
 // render loads and renders the job definition template.
-func (app *App) render(ctx context.Context) (rendered map[string]any, err error) {
+func (app *App) render(ctx context.Context) (map[string]any, error) {
+	if app.definition != nil {
+		return app.definition, nil
+	}
+	var (
+		rendered map[string]any
+		err      error
+	)
+	if app.renderCache != nil {
+		rendered, err = app.renderCache.render(ctx, app)
+	} else {
+		rendered, _, err = app.renderTemplate(ctx)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if checkRefsEnabled(ctx) {
+		if err := checkRefs(rendered, app.config.DefaultParameters); err != nil {
+			return nil, fmt.Errorf("undefined references in job definition template:\n%w", err)
+		}
+	}
+	return rendered, nil
+}
+
+// templatePath returns the path or URL of the job definition template.
+func (app *App) templatePath() (path string, remote bool) {
+	path = app.config.JobDefinition
+	remote = isRemotePath(path)
+	if !remote && !filepath.IsAbs(path) {
+		path = filepath.Join(filepath.Dir(app.configPath), path)
+	}
+	return path, remote
+}
+
+// renderTemplate renders the template with the configured plugins. included
+// lists the files and URLs read by include.
+func (app *App) renderTemplate(ctx context.Context) (rendered map[string]any, included []string, err error) {
 	loader := goconfig.New()
 	if err := setupPlugins(ctx, app.config, loader); err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
-	jobDefPath := app.config.JobDefinition
-	if !filepath.IsAbs(jobDefPath) {
-		jobDefPath = filepath.Join(filepath.Dir(app.configPath), jobDefPath)
+	jobDefPath, remote := app.templatePath()
+	loader.Funcs(includeFuncMap(ctx, app.config, loader, jobDefPath, &included))
+
+	var src []byte
+	if remote {
+		if src, err = fetchRemote(ctx, app.config, jobDefPath); err != nil {
+			return nil, nil, fmt.Errorf("failed to read job definition template: %w", err)
+		}
 	}
 
 	// go-config panics on must_env with undefined variables.
@@ -28,13 +77,287 @@ func (app *App) render(ctx context.Context) (rendered map[string]any, err error)
 		}
 	}()
 
-	if err := loader.LoadWithEnvJSON(&rendered, jobDefPath); err != nil {
-		return nil, fmt.Errorf("failed to render job definition template: %w", err)
+	if remote {
+		err = loader.LoadWithEnvJSONBytes(&rendered, src)
+	} else {
+		err = loader.LoadWithEnvJSON(&rendered, jobDefPath)
 	}
-	return rendered, nil
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to render job definition template: %w", err)
+	}
+	return rendered, included, nil
+}
+
+// renderString renders a config value with the env and must_env template
+// functions.
+func renderString(s string) (out string, err error) {
+	// go-config panics on must_env with undefined variables.
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%v", r)
+		}
+	}()
+	b, err := goconfig.ReadWithEnvBytes([]byte(s))
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// RenderOption holds options for the render command.
+type RenderOption struct {
+	// Output is the output format: "json" (default) or "yaml".
+	Output string
+	// AWSCLI prints the payload as accepted by
+	// `aws batch register-job-definition --cli-input-json`.
+	AWSCLI bool
+	// Raw prints the template as rendered, in camelCase, before the
+	// PascalCase conversion applied for the AWS SDK.
+	Raw bool
+	// NoConvert skips the PascalCase conversion for templates already
+	// written in PascalCase.
+	NoConvert bool
+}
+
+// RenderToDir renders the job definition of every config and writes each to
+// <dir>/<jobDefinitionName>.json (or .yaml with opt.Output "yaml"). Two
+// configs rendering the same job definition name are an error.
+func RenderToDir(ctx context.Context, configPaths []string, dir string, opt RenderOption) error {
+	if opt.AWSCLI {
+		return fmt.Errorf("--aws-cli cannot be combined with --output-dir")
+	}
+	if opt.Raw {
+		return fmt.Errorf("--raw cannot be combined with --output-dir")
+	}
+	ext := ".json"
+	switch opt.Output {
+	case "", "json":
+	case "yaml":
+		ext = ".yaml"
+	default:
+		return fmt.Errorf("unknown output format %q (expected json or yaml)", opt.Output)
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %w", err)
+	}
+
+	written := make(map[string]string, len(configPaths)) // name -> config path
+	for _, configPath := range configPaths {
+		app, err := New(ctx, configPath)
+		if err != nil {
+			return fmt.Errorf("%s: %w", configPath, err)
+		}
+		rendered, err := app.render(ctx)
+		if err != nil {
+			return fmt.Errorf("%s: %w", configPath, err)
+		}
+		converted := convertKeys(rendered, opt.NoConvert)
+		name, _ := converted.(map[string]any)["JobDefinitionName"].(string)
+		if name == "" {
+			return fmt.Errorf("%s: jobDefinitionName is required in job definition", configPath)
+		}
+		if prev, ok := written[name]; ok {
+			return fmt.Errorf("job definition name %q is rendered by both %s and %s", name, prev, configPath)
+		}
+		written[name] = configPath
+
+		var b []byte
+		if ext == ".yaml" {
+			b, err = yaml.Marshal(converted)
+		} else {
+			b, err = json.MarshalIndent(converted, "", "  ")
+			b = append(b, '\n')
+		}
+		if err != nil {
+			return fmt.Errorf("failed to format %s: %w", name, err)
+		}
+		path := filepath.Join(dir, name+ext)
+		if err := os.WriteFile(path, b, 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", path, err)
+		}
+		fmt.Printf("Rendered %s\n", path)
+	}
+	return nil
+}
+
+// maxIncludeDepth limits how deeply include calls may nest.
+const maxIncludeDepth = 10
+
+// includeFuncMap returns the include template function. It renders another
+// file, relative to the including file, through the same loader so fragments
+// can use every template function. Fragments of a remote template are
+// fetched from URLs resolved against it. Include cycles are rejected. Every
+// included path or URL is appended to included.
+func includeFuncMap(ctx context.Context, cfg *Config, loader *goconfig.Loader, templatePath string, included *[]string) template.FuncMap {
+	if !isRemotePath(templatePath) {
+		templatePath = filepath.Clean(templatePath)
+	}
+	chain := []string{templatePath} // files currently being rendered
+	return template.FuncMap{
+		"include": func(name string) (string, error) {
+			path, err := resolveInclude(chain[len(chain)-1], name)
+			if err != nil {
+				return "", fmt.Errorf("failed to include %s: %w", name, err)
+			}
+			if slices.Contains(chain, path) {
+				return "", fmt.Errorf("include cycle: %s -> %s", strings.Join(chain, " -> "), path)
+			}
+			if len(chain) > maxIncludeDepth {
+				return "", fmt.Errorf("include depth exceeds %d at %s", maxIncludeDepth, path)
+			}
+			chain = append(chain, path)
+			defer func() { chain = chain[:len(chain)-1] }()
+			*included = append(*included, path)
+
+			var b []byte
+			if isRemotePath(path) {
+				if b, err = fetchRemote(ctx, cfg, path); err == nil {
+					b, err = loader.ReadWithEnvBytes(b)
+				}
+			} else {
+				b, err = loader.ReadWithEnv(path)
+			}
+			if err != nil {
+				return "", fmt.Errorf("failed to include %s: %w", name, err)
+			}
+			return string(b), nil
+		},
+	}
+}
+
+// resolveInclude resolves an include name against the including file: as a
+// URL reference when that file is remote, as a file path otherwise.
+func resolveInclude(base, name string) (string, error) {
+	if isRemotePath(name) {
+		return name, nil
+	}
+	if isRemotePath(base) {
+		baseURL, err := url.Parse(base)
+		if err != nil {
+			return "", fmt.Errorf("invalid URL %q: %w", base, err)
+		}
+		ref, err := url.Parse(name)
+		if err != nil {
+			return "", fmt.Errorf("invalid include %q: %w", name, err)
+		}
+		return baseURL.ResolveReference(ref).String(), nil
+	}
+	if !filepath.IsAbs(name) {
+		name = filepath.Join(filepath.Dir(base), name)
+	}
+	return filepath.Clean(name), nil
 }
 
 // Render renders the job definition template and prints the result.
-func (app *App) Render(ctx context.Context) error {
-	return app.Register(ctx, RegisterOption{DryRun: true})
+func (app *App) Render(ctx context.Context, opt RenderOption) error {
+	if opt.AWSCLI {
+		rendered, err := app.render(ctx)
+		if err != nil {
+			return err
+		}
+		b, err := awsCLIInputJSON(rendered)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+		return nil
+	}
+	if opt.Raw {
+		return app.renderRaw(ctx, opt.Output)
+	}
+
+	switch opt.Output {
+	case "", "json":
+		return app.Register(ctx, RegisterOption{DryRun: true, NoConvert: opt.NoConvert})
+	case "yaml":
+		rendered, err := app.render(ctx)
+		if err != nil {
+			return err
+		}
+		b, err := yaml.Marshal(convertKeys(rendered, opt.NoConvert))
+		if err != nil {
+			return fmt.Errorf("failed to format YAML: %w", err)
+		}
+		fmt.Print(string(b))
+		return nil
+	default:
+		return fmt.Errorf("unknown output format %q (expected json or yaml)", opt.Output)
+	}
+}
+
+// renderRaw prints the rendered template without converting keys, to tell
+// templating problems from conversion problems.
+func (app *App) renderRaw(ctx context.Context, output string) error {
+	rendered, err := app.render(ctx)
+	if err != nil {
+		return err
+	}
+	var b []byte
+	switch output {
+	case "", "json":
+		b, err = json.MarshalIndent(rendered, "", "  ")
+		b = append(b, '\n')
+	case "yaml":
+		b, err = yaml.Marshal(rendered)
+	default:
+		return fmt.Errorf("unknown output format %q (expected json or yaml)", output)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to format rendered template: %w", err)
+	}
+	fmt.Print(string(b))
+	return nil
+}
+
+// awsCLIInputJSON converts the rendered template into a
+// RegisterJobDefinitionInput and serializes it back with the API's camelCase
+// member names. Fields the SDK does not know are dropped, so the result is
+// exactly what register would send.
+func awsCLIInputJSON(rendered map[string]any) ([]byte, error) {
+	b, err := json.Marshal(walkMap(rendered, toPascalCase))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal job definition: %w", err)
+	}
+	var input batch.RegisterJobDefinitionInput
+	if err := json.Unmarshal(b, &input); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal into RegisterJobDefinitionInput: %w", err)
+	}
+
+	b, err = json.Marshal(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal RegisterJobDefinitionInput: %w", err)
+	}
+	var m map[string]any
+	if err := json.Unmarshal(b, &m); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal RegisterJobDefinitionInput: %w", err)
+	}
+	out, err := json.MarshalIndent(walkMap(pruneUnset(m), toCamelCase), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to format JSON: %w", err)
+	}
+	return out, nil
+}
+
+// pruneUnset removes null values and empty strings (unset SDK pointers and
+// enums) from a JSON-decoded value.
+func pruneUnset(v any) any {
+	switch val := v.(type) {
+	case map[string]any:
+		result := make(map[string]any, len(val))
+		for k, child := range val {
+			if child == nil || child == "" {
+				continue
+			}
+			result[k] = pruneUnset(child)
+		}
+		return result
+	case []any:
+		result := make([]any, len(val))
+		for i, child := range val {
+			result[i] = pruneUnset(child)
+		}
+		return result
+	default:
+		return v
+	}
 }
@@ -2,10 +2,16 @@ package batcha
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
 	"os"
 	"path/filepath"
 	"strings"
 	"testing"
+
+	"gopkg.in/yaml.v2"
 )
 
 func TestRender(t *testing.T) {
@@ -81,3 +87,276 @@ func TestRender_MustEnvPanic(t *testing.T) {
 		t.Errorf("error should mention the variable name, got: %v", err)
 	}
 }
+
+func TestRender_Output(t *testing.T) {
+	t.Setenv("TEST_JOB_NAME", "render-job")
+
+	app, err := New(context.Background(), filepath.Join("testdata", "config.yml"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	render := func(output string) string {
+		t.Helper()
+		return captureStdout(t, func() {
+			if err := app.Render(context.Background(), RenderOption{Output: output}); err != nil {
+				t.Errorf("Render(%s) failed: %v", output, err)
+			}
+		})
+	}
+
+	var fromJSON map[string]any
+	if out := render("json"); json.Unmarshal([]byte(out), &fromJSON) != nil {
+		t.Fatalf("json output is not JSON:\n%s", out)
+	}
+	out := render("yaml")
+	if strings.HasPrefix(strings.TrimSpace(out), "{") {
+		t.Errorf("yaml output looks like JSON:\n%s", out)
+	}
+	var fromYAML map[string]any
+	if err := yaml.Unmarshal([]byte(out), &fromYAML); err != nil {
+		t.Fatalf("yaml output is not YAML: %v\n%s", err, out)
+	}
+	for _, key := range []string{"JobDefinitionName", "Type", "ContainerProperties", "Tags", "Parameters"} {
+		if _, ok := fromYAML[key]; !ok {
+			t.Errorf("yaml output is missing top-level key %s:\n%s", key, out)
+		}
+	}
+	if fromYAML["JobDefinitionName"] != "render-job" || fromYAML["JobDefinitionName"] != fromJSON["JobDefinitionName"] {
+		t.Errorf("JobDefinitionName = %v (json %v), want render-job", fromYAML["JobDefinitionName"], fromJSON["JobDefinitionName"])
+	}
+	// Tag and parameter keys are user data and keep their case.
+	if tags, _ := fromYAML["Tags"].(map[any]any); tags["managedBy"] != "batcha" || tags["project"] != "example" {
+		t.Errorf("Tags = %v, want the template's keys unconverted", fromYAML["Tags"])
+	}
+	if params, _ := fromYAML["Parameters"].(map[any]any); params["inputFile"] != "s3://bucket/input.csv" {
+		t.Errorf("Parameters = %v, want the template's keys unconverted", fromYAML["Parameters"])
+	}
+
+	if err := app.Render(context.Background(), RenderOption{Output: "toml"}); err == nil {
+		t.Error("expected error for unknown output format")
+	}
+}
+
+func TestRender_Raw(t *testing.T) {
+	t.Setenv("TEST_JOB_NAME", "raw-job")
+
+	app, err := New(context.Background(), filepath.Join("testdata", "config.yml"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	raw := captureStdout(t, func() {
+		if err := app.Render(context.Background(), RenderOption{Raw: true}); err != nil {
+			t.Fatalf("Render(raw) failed: %v", err)
+		}
+	})
+	converted := captureStdout(t, func() {
+		if err := app.Render(context.Background(), RenderOption{}); err != nil {
+			t.Fatalf("Render failed: %v", err)
+		}
+	})
+
+	var rawDef, convertedDef map[string]any
+	if err := json.Unmarshal([]byte(raw), &rawDef); err != nil {
+		t.Fatalf("raw output is not JSON: %v\n%s", err, raw)
+	}
+	if err := json.Unmarshal([]byte(converted), &convertedDef); err != nil {
+		t.Fatalf("converted output is not JSON: %v\n%s", err, converted)
+	}
+	if rawDef["jobDefinitionName"] != "raw-job" || rawDef["JobDefinitionName"] != nil {
+		t.Errorf("raw output should keep camelCase keys, got:\n%s", raw)
+	}
+	if convertedDef["JobDefinitionName"] != "raw-job" || convertedDef["jobDefinitionName"] != nil {
+		t.Errorf("converted output should use PascalCase keys, got:\n%s", converted)
+	}
+
+	if err := RenderToDir(context.Background(), []string{filepath.Join("testdata", "config.yml")}, t.TempDir(), RenderOption{Raw: true}); err == nil {
+		t.Error("expected error for --raw with --output-dir")
+	}
+}
+
+func TestAWSCLIInputJSON(t *testing.T) {
+	rendered := map[string]any{
+		"jobDefinitionName": "cli-job",
+		"type":              "container",
+		"unknownField":      "dropped",
+		"containerProperties": map[string]any{
+			"image":   "nginx:latest",
+			"command": []any{"echo", "hello"},
+			"resourceRequirements": []any{
+				map[string]any{"type": "VCPU", "value": "1"},
+			},
+		},
+		"tags": map[string]any{"Team": "data"},
+	}
+
+	b, err := awsCLIInputJSON(rendered)
+	if err != nil {
+		t.Fatalf("awsCLIInputJSON failed: %v", err)
+	}
+
+	want := `{
+  "containerProperties": {
+    "command": [
+      "echo",
+      "hello"
+    ],
+    "image": "nginx:latest",
+    "resourceRequirements": [
+      {
+        "type": "VCPU",
+        "value": "1"
+      }
+    ]
+  },
+  "jobDefinitionName": "cli-job",
+  "tags": {
+    "Team": "data"
+  },
+  "type": "container"
+}`
+	if string(b) != want {
+		t.Errorf("awsCLIInputJSON =\n%s\nwant\n%s", b, want)
+	}
+}
+
+func TestRender_Include(t *testing.T) {
+	t.Setenv("BATCHA_TEST_APP_ENV", "staging")
+	dir := t.TempDir()
+	files := map[string]string{
+		"batcha.yml": "region: us-east-1\njob_definition: job.json\n",
+		"job.json": `{
+  "jobDefinitionName": "include-job",
+  "containerProperties": {
+    "environment": {{ include "fragments/env.json" }}
+  }
+}`,
+		"fragments/env.json": "[{\"name\": \"APP_ENV\", \"value\": \"{{ must_env `BATCHA_TEST_APP_ENV` }}\"}]",
+	}
+	for name, content := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app, err := New(context.Background(), filepath.Join(dir, "batcha.yml"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	rendered, err := app.render(context.Background())
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+
+	env := rendered["containerProperties"].(map[string]any)["environment"].([]any)
+	value := env[0].(map[string]any)["value"]
+	if value != "staging" {
+		t.Errorf("included environment value = %v, want staging", value)
+	}
+}
+
+func TestRender_IncludeCycle(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"batcha.yml": "region: us-east-1\njob_definition: job.json\n",
+		"job.json":   `{"jobDefinitionName": "cycle-job", "x": {{ include "a.json" }}}`,
+		"a.json":     `{{ include "job.json" }}`,
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	app, err := New(context.Background(), filepath.Join(dir, "batcha.yml"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	_, err = app.render(context.Background())
+	if err == nil || !strings.Contains(err.Error(), "include cycle") {
+		t.Errorf("expected include cycle error, got: %v", err)
+	}
+}
+
+func TestRender_IncludeRemote(t *testing.T) {
+	files := map[string]string{
+		"/defs/job.json":                 `{"jobDefinitionName": "remote-job", "containerProperties": {{ include "fragments/container.json" }}}`,
+		"/defs/fragments/container.json": `{"image": "nginx", "environment": {{ include "../env.json" }}}`,
+		"/defs/env.json":                 `[{"name": "APP_ENV", "value": "prod"}]`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		content, ok := files[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		fmt.Fprint(w, content)
+	}))
+	defer srv.Close()
+
+	app := &App{config: &Config{Region: "us-east-1", JobDefinition: srv.URL + "/defs/job.json"}}
+	rendered, err := app.render(context.Background())
+	if err != nil {
+		t.Fatalf("render failed: %v", err)
+	}
+	container := rendered["containerProperties"].(map[string]any)
+	if container["image"] != "nginx" {
+		t.Errorf("included image = %v, want nginx", container["image"])
+	}
+	env := container["environment"].([]any)
+	if value := env[0].(map[string]any)["value"]; value != "prod" {
+		t.Errorf("nested included environment value = %v, want prod", value)
+	}
+}
+
+func TestRenderToDir(t *testing.T) {
+	src := t.TempDir()
+	writeConfig := func(file, name string) string {
+		t.Helper()
+		jobDef := fmt.Sprintf(`{"jobDefinitionName": %q, "type": "container"}`, name)
+		if err := os.WriteFile(filepath.Join(src, file+".json"), []byte(jobDef), 0644); err != nil {
+			t.Fatal(err)
+		}
+		cfgPath := filepath.Join(src, file+".yml")
+		if err := os.WriteFile(cfgPath, []byte("region: us-east-1\njob_definition: "+file+".json\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return cfgPath
+	}
+	a := writeConfig("a", "job-a")
+	b := writeConfig("b", "job-b")
+	dup := writeConfig("dup", "job-a")
+
+	out := filepath.Join(t.TempDir(), "build")
+	captureStdout(t, func() {
+		if err := RenderToDir(context.Background(), []string{a, b}, out, RenderOption{}); err != nil {
+			t.Error(err)
+		}
+	})
+	for _, name := range []string{"job-a", "job-b"} {
+		b, err := os.ReadFile(filepath.Join(out, name+".json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got map[string]any
+		if err := json.Unmarshal(b, &got); err != nil {
+			t.Fatal(err)
+		}
+		if got["JobDefinitionName"] != name {
+			t.Errorf("%s.json has JobDefinitionName %v", name, got["JobDefinitionName"])
+		}
+	}
+
+	var err error
+	captureStdout(t, func() {
+		err = RenderToDir(context.Background(), []string{a, dup}, out, RenderOption{})
+	})
+	if err == nil || !strings.Contains(err.Error(), `"job-a" is rendered by both`) {
+		t.Errorf("expected a name collision error, got %v", err)
+	}
+}
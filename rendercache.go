@@ -0,0 +1,174 @@
+package batcha
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"slices"
+	"sync"
+)
+
+// renderCache memoizes rendered templates for library callers that render
+// the same inputs repeatedly, so plugins (tfstate, SSM) are not evaluated
+// again. Entries are keyed by the config, the template source and the
+// environment, and are dropped when an included file changes. Remote
+// templates are keyed by URL and are not fetched again until
+// ClearRenderCache. At most maxRenderCacheEntries entries are kept; the
+// oldest is evicted first.
+type renderCache struct {
+	mu      sync.Mutex
+	entries map[string]renderCacheEntry
+	order   []string // keys of entries, oldest first
+}
+
+// maxRenderCacheEntries caps the entries of a render cache (overridden in
+// tests).
+var maxRenderCacheEntries = 64
+
+type renderCacheEntry struct {
+	rendered map[string]any
+	// includes maps each included local file to the hash of its content.
+	includes map[string][sha256.Size]byte
+}
+
+// EnableRenderCache makes the app cache rendered templates. Values read by
+// plugins (tfstate, SSM) are not part of the cache key; call
+// ClearRenderCache to pick up changes to them.
+func (app *App) EnableRenderCache() {
+	if app.renderCache == nil {
+		app.renderCache = &renderCache{}
+	}
+}
+
+// ClearRenderCache drops every cached render.
+func (app *App) ClearRenderCache() {
+	if app.renderCache == nil {
+		return
+	}
+	app.renderCache.mu.Lock()
+	defer app.renderCache.mu.Unlock()
+	app.renderCache.entries = nil
+	app.renderCache.order = nil
+}
+
+// render returns a copy of the cached render of app's template, rendering
+// and caching it on a miss.
+func (c *renderCache) render(ctx context.Context, app *App) (map[string]any, error) {
+	key, err := app.renderCacheKey()
+	if err != nil {
+		return nil, err
+	}
+
+	c.mu.Lock()
+	entry, ok := c.entries[key]
+	c.mu.Unlock()
+	if ok && includesUnchanged(entry.includes) {
+		return cloneJSON(entry.rendered).(map[string]any), nil
+	}
+
+	rendered, included, err := app.renderTemplate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	entry = renderCacheEntry{rendered: cloneJSON(rendered).(map[string]any), includes: map[string][sha256.Size]byte{}}
+	for _, path := range included {
+		if isRemotePath(path) {
+			continue // cached until ClearRenderCache, like a remote template
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return rendered, nil // not cacheable, but rendered fine
+		}
+		entry.includes[path] = sha256.Sum256(b)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.put(key, entry)
+	return rendered, nil
+}
+
+// put stores entry under key, evicting the oldest entries beyond
+// maxRenderCacheEntries. The caller must hold c.mu.
+func (c *renderCache) put(key string, entry renderCacheEntry) {
+	if c.entries == nil {
+		c.entries = map[string]renderCacheEntry{}
+	}
+	if _, ok := c.entries[key]; !ok {
+		c.order = append(c.order, key)
+	}
+	c.entries[key] = entry
+	for len(c.order) > maxRenderCacheEntries {
+		delete(c.entries, c.order[0])
+		c.order = c.order[1:]
+	}
+}
+
+// renderCacheKey hashes everything that affects rendering except plugin
+// values and included files. A remote template contributes only its URL, so
+// a lookup does not fetch it.
+func (app *App) renderCacheKey() (string, error) {
+	cfg, err := json.Marshal(app.config)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal config: %w", err)
+	}
+	path, remote := app.templatePath()
+	var src []byte
+	if !remote {
+		if src, err = os.ReadFile(path); err != nil {
+			return "", fmt.Errorf("failed to read job definition template: %w", err)
+		}
+	}
+	env := os.Environ()
+	slices.Sort(env)
+
+	h := sha256.New()
+	for _, part := range [][]byte{cfg, []byte(app.configPath), []byte(path), src} {
+		h.Write(part)
+		h.Write([]byte{0})
+	}
+	for _, e := range env {
+		h.Write([]byte(e))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// includesUnchanged reports whether every included file still has the
+// recorded content.
+func includesUnchanged(includes map[string][sha256.Size]byte) bool {
+	for path, sum := range includes {
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return false
+		}
+		if got := sha256.Sum256(b); !bytes.Equal(got[:], sum[:]) {
+			return false
+		}
+	}
+	return true
+}
+
+// cloneJSON deep-copies a decoded JSON value so callers cannot modify a
+// cached render.
+func cloneJSON(v any) any {
+	switch v := v.(type) {
+	case map[string]any:
+		m := make(map[string]any, len(v))
+		for k, x := range v {
+			m[k] = cloneJSON(x)
+		}
+		return m
+	case []any:
+		s := make([]any, len(v))
+		for i, x := range v {
+			s[i] = cloneJSON(x)
+		}
+		return s
+	}
+	return v
+}
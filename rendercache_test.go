@@ -0,0 +1,133 @@
+package batcha
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+const testTFState = `{
+  "version": 4, "terraform_version": "1.5.0", "serial": 1, "lineage": "test", "outputs": {},
+  "resources": [{
+    "mode": "managed", "type": "aws_s3_bucket", "name": "data",
+    "provider": "provider[\"registry.terraform.io/hashicorp/aws\"]",
+    "instances": [{"schema_version": 0, "attributes": {"bucket": "my-bucket"}}]
+  }]
+}`
+
+func TestRenderCache(t *testing.T) {
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		fmt.Fprint(w, testTFState)
+	}))
+	defer srv.Close()
+
+	dir := t.TempDir()
+	write := func(name, content string) {
+		t.Helper()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("batcha.yml", "region: us-east-1\njob_definition: job.json\nplugins:\n  - name: tfstate\n    config:\n      url: "+srv.URL+"/terraform.tfstate\n")
+	write("job.json", `{"jobDefinitionName": "cached", "tags": {{ include "tags.json" }}, "containerProperties": {"environment": [{"name": "BUCKET", "value": "{{ tfstate "aws_s3_bucket.data.bucket" }}"}]}}`)
+	write("tags.json", `{"team": "data"}`)
+
+	ctx := context.Background()
+	app, err := New(ctx, filepath.Join(dir, "batcha.yml"))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	app.EnableRenderCache()
+
+	render := func() map[string]any {
+		t.Helper()
+		rendered, err := app.render(ctx)
+		if err != nil {
+			t.Fatalf("render failed: %v", err)
+		}
+		return rendered
+	}
+
+	first := render()
+	first["jobDefinitionName"] = "modified by the caller"
+	second := render()
+	if n := fetches.Load(); n != 1 {
+		t.Errorf("tfstate fetched %d times, want 1 (cache hit)", n)
+	}
+	if second["jobDefinitionName"] != "cached" {
+		t.Errorf("cached render was modified through a returned map: %v", second["jobDefinitionName"])
+	}
+
+	write("tags.json", `{"team": "platform"}`)
+	third := render()
+	if n := fetches.Load(); n != 2 {
+		t.Errorf("tfstate fetched %d times after an include changed, want 2", n)
+	}
+	if tags := third["tags"].(map[string]any); tags["team"] != "platform" {
+		t.Errorf("tags = %v, want the changed include", tags)
+	}
+
+	t.Setenv("BATCHA_RENDER_CACHE_TEST", "1")
+	render()
+	if n := fetches.Load(); n != 3 {
+		t.Errorf("tfstate fetched %d times after the environment changed, want 3", n)
+	}
+
+	app.ClearRenderCache()
+	render()
+	if n := fetches.Load(); n != 4 {
+		t.Errorf("tfstate fetched %d times after ClearRenderCache, want 4", n)
+	}
+}
+
+func TestRenderCache_Remote(t *testing.T) {
+	orig := maxRenderCacheEntries
+	maxRenderCacheEntries = 2
+	defer func() { maxRenderCacheEntries = orig }()
+
+	var fetches atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		fetches.Add(1)
+		fmt.Fprint(w, `{"jobDefinitionName": "{{ env "BATCHA_RENDER_CACHE_TEST" }}"}`)
+	}))
+	defer srv.Close()
+
+	ctx := context.Background()
+	app := &App{config: &Config{Region: "us-east-1", JobDefinition: srv.URL + "/job.json"}}
+	app.EnableRenderCache()
+	render := func(env string, wantFetches int32) {
+		t.Helper()
+		t.Setenv("BATCHA_RENDER_CACHE_TEST", env)
+		rendered, err := app.render(ctx)
+		if err != nil {
+			t.Fatalf("render failed: %v", err)
+		}
+		if rendered["jobDefinitionName"] != env {
+			t.Errorf("jobDefinitionName = %v, want %s", rendered["jobDefinitionName"], env)
+		}
+		if n := fetches.Load(); n != wantFetches {
+			t.Errorf("template fetched %d times after rendering %s, want %d", n, env, wantFetches)
+		}
+	}
+
+	render("a", 1)
+	render("a", 1) // a hit does not fetch the template
+	render("b", 2)
+	render("c", 3) // evicts a
+	render("c", 3)
+	render("b", 3)
+	render("a", 4)
+	if n := len(app.renderCache.entries); n != 2 {
+		t.Errorf("cache holds %d entries, want 2", n)
+	}
+
+	app.ClearRenderCache()
+	render("a", 5)
+}
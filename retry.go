@@ -0,0 +1,84 @@
+package batcha
+
+import (
+	"context"
+	"fmt"
+	"maps"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+)
+
+// retryFailed resubmits the failed job opt.RetryFailed.
+func (app *App) retryFailed(ctx context.Context, opt RunOption) error {
+	client, err := app.newBatchClient(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	input, err := retryInput(ctx, client, opt.RetryFailed)
+	if err != nil {
+		return err
+	}
+	if opt.Output != "json" {
+		fmt.Printf("Retrying failed job %s\n", opt.RetryFailed)
+	}
+	return app.submit(ctx, client, input, opt, runResult{RetriedJobID: opt.RetryFailed})
+}
+
+// retryInput describes a failed job and returns a submission with the same
+// definition revision, queue, name, parameters and settings. The container
+// command, environment and resources the job ran with are passed as
+// overrides.
+func retryInput(ctx context.Context, client batchAPI, jobID string) (*batch.SubmitJobInput, error) {
+	out, err := client.DescribeJobs(ctx, &batch.DescribeJobsInput{Jobs: []string{jobID}})
+	if err != nil {
+		return nil, withHint(fmt.Errorf("failed to describe job %s: %w", jobID, err), opDescribe)
+	}
+	if len(out.Jobs) == 0 {
+		return nil, fmt.Errorf("job %s not found", jobID)
+	}
+	job := out.Jobs[0]
+	if job.Status != batchTypes.JobStatusFailed {
+		return nil, fmt.Errorf("job %s is %s, not FAILED; only failed jobs can be retried", jobID, job.Status)
+	}
+	if job.NodeProperties != nil {
+		return nil, fmt.Errorf("job %s is a multinode job; --retry-failed supports container jobs only", jobID)
+	}
+
+	input := &batch.SubmitJobInput{
+		JobName:                    job.JobName,
+		JobQueue:                   job.JobQueue,
+		JobDefinition:              job.JobDefinition,
+		Parameters:                 job.Parameters,
+		PropagateTags:              job.PropagateTags,
+		RetryStrategy:              job.RetryStrategy,
+		SchedulingPriorityOverride: job.SchedulingPriority,
+		ShareIdentifier:            job.ShareIdentifier,
+		Timeout:                    job.Timeout,
+	}
+	// Tags with the reserved aws: prefix are added by AWS and cannot be set.
+	tags := maps.Clone(job.Tags)
+	maps.DeleteFunc(tags, func(k, _ string) bool { return strings.HasPrefix(k, "aws:") })
+	if len(tags) > 0 {
+		input.Tags = tags
+	}
+	if ap := job.ArrayProperties; ap != nil && ap.Size != nil {
+		input.ArrayProperties = &batchTypes.ArrayProperties{Size: ap.Size}
+	}
+	if c := job.Container; c != nil {
+		overrides := &batchTypes.ContainerOverrides{
+			Command:              c.Command,
+			ResourceRequirements: c.ResourceRequirements,
+		}
+		// AWS_BATCH_* variables are set by AWS Batch and cannot be overridden.
+		for _, kv := range c.Environment {
+			if !strings.HasPrefix(aws.ToString(kv.Name), "AWS_BATCH_") {
+				overrides.Environment = append(overrides.Environment, kv)
+			}
+		}
+		input.ContainerOverrides = overrides
+	}
+	return input, nil
+}
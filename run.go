@@ -2,12 +2,24 @@ package batcha
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"strings"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/batch"
 	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	cwlTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"gopkg.in/yaml.v2"
 )
 
 // RunOption holds options for the run command.
@@ -16,27 +28,155 @@ type RunOption struct {
 	JobName    string
 	Parameters map[string]string
 	Wait       bool
+	// PollLogs prints the newest log lines of the job on every --wait poll.
+	PollLogs bool
+
+	// GenerateName appends a timestamp and random suffix to the job name.
+	GenerateName bool
+	// AutoQueue picks the job queue tagged batcha/default=true when neither
+	// --job-queue nor job_queue is set.
+	AutoQueue bool
+
+	// Diff compares the local template with the active definition before
+	// submitting and aborts when they differ, unless Force is set.
+	Diff  bool
+	Force bool
+
+	// FromStatusFile is a JSON status file listing one submission per entry
+	// (see runBatchEntry). Entries are submitted with at most Concurrency in
+	// flight and the updated status file is printed to stdout.
+	FromStatusFile string
+	Concurrency    int
+
+	// ParameterFile is a YAML or JSON file of parameters. Parameters override it.
+	ParameterFile string
+
+	// ParametersFromJob is the ID of a previous job whose parameters are
+	// reused. Explicit Parameters take precedence.
+	ParametersFromJob string
+
+	// Output is the output format: "text" (default) or "json".
+	Output string
+
+	// CommandFile is a script run as the container command via
+	// `<Shell> -c <contents>`. Shell defaults to "sh".
+	CommandFile string
+	Shell       string
+
+	// JobDefinitionArn submits against this exact revision instead of the
+	// latest active revision of the rendered definition.
+	JobDefinitionArn string
+
+	// TimeoutSeconds overrides the job definition's attempt timeout for this
+	// submission (0 keeps the definition's).
+	TimeoutSeconds int32
+
+	// ShareIdentifier is the fair-share identifier of the job, required by
+	// job queues with a scheduling policy.
+	ShareIdentifier string
+
+	// RetryFailed is the ID of a failed job to resubmit with the same
+	// definition revision, queue, parameters and overrides. The template
+	// is not rendered.
+	RetryFailed string
+}
+
+// runResult is the submission result printed by run --output json.
+type runResult struct {
+	JobName          string `json:"jobName"`
+	JobID            string `json:"jobId"`
+	JobQueue         string `json:"jobQueue"`
+	JobDefinitionArn string `json:"jobDefinitionArn"`
+
+	// Status and ExitCode are set with --wait.
+	Status   string `json:"status,omitempty"`
+	ExitCode *int32 `json:"exitCode,omitempty"`
+
+	// RetriedJobID is the failed job resubmitted with --retry-failed.
+	RetriedJobID string `json:"retriedJobId,omitempty"`
+}
+
+// maxJobNameLength is the longest job name AWS Batch accepts.
+const maxJobNameLength = 128
+
+// generateJobName appends a -YYYYMMDD-HHMMSS-xxxx suffix (UTC timestamp and
+// four random hex digits) to base, truncating base so the result stays within
+// maxJobNameLength.
+func generateJobName(base string, now time.Time) string {
+	b := make([]byte, 2)
+	_, _ = rand.Read(b) // never returns an error
+	suffix := fmt.Sprintf("-%s-%s", now.UTC().Format("20060102-150405"), hex.EncodeToString(b))
+	if len(base) > maxJobNameLength-len(suffix) {
+		base = base[:maxJobNameLength-len(suffix)]
+	}
+	return base + suffix
 }
 
+// jobPollInterval is how often --wait checks the job status.
+var jobPollInterval = 10 * time.Second
+
 // Run submits a job using the latest active job definition.
 func (app *App) Run(ctx context.Context, opt RunOption) error {
-	// Resolve job queue: CLI flag > config > error
+	switch opt.Output {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("unknown output format %q (expected text or json)", opt.Output)
+	}
+
+	if opt.FromStatusFile != "" && opt.Wait {
+		return fmt.Errorf("--from-status-file cannot be combined with --wait")
+	}
+	if opt.Force && !opt.Diff {
+		return fmt.Errorf("--force requires --diff")
+	}
+	if opt.PollLogs && !opt.Wait {
+		return fmt.Errorf("--poll-logs requires --wait")
+	}
+	if opt.TimeoutSeconds != 0 && opt.TimeoutSeconds < minAttemptDurationSeconds {
+		return fmt.Errorf("--timeout-seconds must be at least %d, got %d", minAttemptDurationSeconds, opt.TimeoutSeconds)
+	}
+	if opt.Diff {
+		// The diff is printed to stdout, which must stay parseable.
+		if opt.Output == "json" || opt.FromStatusFile != "" {
+			return fmt.Errorf("--diff cannot be combined with --output json or --from-status-file")
+		}
+		if opt.JobDefinitionArn != "" {
+			return fmt.Errorf("--diff cannot be combined with --job-definition-arn")
+		}
+	}
+
+	if opt.RetryFailed != "" {
+		return app.retryFailed(ctx, opt)
+	}
+
+	// Resolve job queue: CLI flag > config > --auto-queue > error
 	if opt.JobQueue == "" {
 		opt.JobQueue = app.config.JobQueue
 	}
-	if opt.JobQueue == "" {
-		return fmt.Errorf("job queue is required: set job_queue in config or use --job-queue flag")
+	if opt.JobQueue == "" && opt.AutoQueue {
+		client, err := app.newBatchClient(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load AWS config: %w", err)
+		}
+		if opt.JobQueue, err = autoJobQueue(ctx, client); err != nil {
+			return err
+		}
 	}
-
-	rendered, err := app.render(ctx)
-	if err != nil {
-		return err
+	if opt.JobQueue == "" {
+		return fmt.Errorf("job queue is required: set job_queue in config or use --job-queue (or --auto-queue) flag")
 	}
-	converted := walkMap(rendered, toPascalCase)
 
-	name, _ := converted.(map[string]any)["JobDefinitionName"].(string)
-	if name == "" {
-		return fmt.Errorf("jobDefinitionName is required in job definition")
+	if opt.Diff {
+		if err := app.diff(ctx, DiffOption{}, printDiffRecords); err != nil {
+			var diffErr *DiffError
+			if !errors.As(err, &diffErr) {
+				return err
+			}
+			if !opt.Force {
+				return fmt.Errorf("the local template differs from the active job definition; run `batcha register` first or pass --force to submit anyway")
+			}
+			fmt.Println("Submitting despite differences (--force).")
+		}
 	}
 
 	client, err := app.newBatchClient(ctx)
@@ -44,83 +184,340 @@ func (app *App) Run(ctx context.Context, opt RunOption) error {
 		return fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
-	// Fetch the latest active revision ARN
-	out, err := client.DescribeJobDefinitions(ctx, &batch.DescribeJobDefinitionsInput{
-		JobDefinitionName: aws.String(name),
-		Status:            aws.String("ACTIVE"),
-	})
+	jobDefArn, name, err := app.resolveJobDefinition(ctx, client, opt.JobDefinitionArn)
 	if err != nil {
-		return fmt.Errorf("failed to describe job definitions: %w", err)
-	}
-	if len(out.JobDefinitions) == 0 {
-		return fmt.Errorf("no active job definition found for %q", name)
+		return err
 	}
-	latest := pickLatestRevision(out.JobDefinitions)
 
+	// Precedence: --job-name > default_job_name > job definition name
 	jobName := opt.JobName
+	if jobName == "" && app.config.DefaultJobName != "" {
+		if jobName, err = renderString(app.config.DefaultJobName); err != nil {
+			return fmt.Errorf("failed to render default_job_name: %w", err)
+		}
+	}
 	if jobName == "" {
 		jobName = name
 	}
+	baseName := jobName
+	if opt.GenerateName {
+		jobName = generateJobName(jobName, time.Now())
+	}
+
+	// Precedence: default_parameters < previous job < parameter file < flags
+	layers := []map[string]string{app.config.DefaultParameters}
+	if opt.ParametersFromJob != "" {
+		fromJob, err := parametersFromJob(ctx, client, opt.ParametersFromJob)
+		if err != nil {
+			return err
+		}
+		layers = append(layers, fromJob)
+	}
+	if opt.ParameterFile != "" {
+		fromFile, err := readParameterFile(opt.ParameterFile)
+		if err != nil {
+			return err
+		}
+		layers = append(layers, fromFile)
+	}
+	params := mergeParameters(append(layers, opt.Parameters)...)
 
 	input := &batch.SubmitJobInput{
-		JobDefinition: latest.JobDefinitionArn,
+		JobDefinition: aws.String(jobDefArn),
 		JobQueue:      aws.String(opt.JobQueue),
 		JobName:       aws.String(jobName),
 	}
-	if len(opt.Parameters) > 0 {
-		input.Parameters = opt.Parameters
+	if len(params) > 0 {
+		input.Parameters = params
 	}
+	if opt.ShareIdentifier != "" {
+		input.ShareIdentifier = aws.String(opt.ShareIdentifier)
+	}
+	if opt.CommandFile != "" {
+		command, err := commandFromFile(opt.CommandFile, opt.Shell)
+		if err != nil {
+			return err
+		}
+		input.ContainerOverrides = &batchTypes.ContainerOverrides{Command: command}
+	}
+	if opt.TimeoutSeconds > 0 {
+		input.Timeout = &batchTypes.JobTimeout{AttemptDurationSeconds: aws.Int32(opt.TimeoutSeconds)}
+		// The global --timeout would stop waiting before the job can time out.
+		if deadline, ok := ctx.Deadline(); ok && opt.Wait && time.Until(deadline) < time.Duration(opt.TimeoutSeconds)*time.Second {
+			fmt.Fprintf(os.Stderr, "Note: --timeout ends the wait in %s, before the job's %ds timeout; the job keeps running on AWS.\n",
+				time.Until(deadline).Round(time.Second), opt.TimeoutSeconds)
+		}
+	}
+
+	if opt.FromStatusFile != "" {
+		return runBatch(ctx, client, input, baseName, opt)
+	}
+	return app.submit(ctx, client, input, opt, runResult{})
+}
 
+// submit submits input, prints the result and, with opt.Wait, waits for the
+// job. res carries fields set by the caller (RetriedJobID).
+func (app *App) submit(ctx context.Context, client batchAPI, input *batch.SubmitJobInput, opt RunOption, res runResult) error {
+	queue := aws.ToString(input.JobQueue)
 	result, err := client.SubmitJob(ctx, input)
 	if err != nil {
-		return fmt.Errorf("failed to submit job: %w", err)
+		// Fair-share queues reject jobs without a share identifier with an
+		// unhelpful message; check the queue only once submission failed.
+		if input.ShareIdentifier == nil {
+			if policy := schedulingPolicyArn(ctx, client, queue); policy != "" {
+				return fmt.Errorf("failed to submit job: job queue %q uses the fair-share scheduling policy %s; pass --share-identifier: %w", queue, policy, err)
+			}
+		}
+		return withHint(fmt.Errorf("failed to submit job: %w", err), opSubmit)
 	}
 
-	fmt.Printf("Submitted job: %s (ID: %s)\n", aws.ToString(result.JobName), aws.ToString(result.JobId))
+	jsonOutput := opt.Output == "json"
+	res.JobName = aws.ToString(result.JobName)
+	res.JobID = aws.ToString(result.JobId)
+	res.JobQueue = queue
+	res.JobDefinitionArn = aws.ToString(input.JobDefinition)
+	if !jsonOutput {
+		fmt.Printf("Submitted job: %s (ID: %s)\n", res.JobName, res.JobID)
+	}
 
 	if !opt.Wait {
+		if jsonOutput {
+			return printJSON(res)
+		}
 		return nil
 	}
 
-	return app.waitForJob(ctx, client, aws.ToString(result.JobId))
+	// Keep stdout parseable in JSON mode by reporting progress on stderr.
+	progress := io.Writer(os.Stdout)
+	if jsonOutput {
+		progress = os.Stderr
+	}
+	var poller *logPoller
+	if opt.PollLogs {
+		cwlClient, err := app.newLogsClient(ctx)
+		if err != nil {
+			return fmt.Errorf("failed to load AWS config: %w", err)
+		}
+		poller = &logPoller{client: cwlClient, w: progress}
+	}
+	job, err := app.waitForJob(ctx, client, res.JobID, progress, poller)
+	if jsonOutput && job != nil {
+		res.Status = string(job.Status)
+		res.ExitCode = jobExitCode(job)
+		if perr := printJSON(res); perr != nil {
+			return perr
+		}
+	}
+	return err
+}
+
+func printJSON(v any) error {
+	b, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Errorf("failed to marshal JSON: %w", err)
+	}
+	fmt.Println(string(b))
+	return nil
 }
 
-func (app *App) waitForJob(ctx context.Context, client *batch.Client, jobID string) error {
-	fmt.Printf("Waiting for job %s...\n", jobID)
+// jobExitCode returns the container exit code of a finished job, if any.
+func jobExitCode(job *batchTypes.JobDetail) *int32 {
+	if job.Container != nil && job.Container.ExitCode != nil {
+		return job.Container.ExitCode
+	}
+	if n := len(job.Attempts); n > 0 && job.Attempts[n-1].Container != nil {
+		return job.Attempts[n-1].Container.ExitCode
+	}
+	return nil
+}
+
+// jobDefinitionArnPattern matches a job definition revision ARN in any
+// partition and captures the name.
+var jobDefinitionArnPattern = regexp.MustCompile(`^arn:aws[a-z-]*:batch:[a-z0-9-]+:\d{12}:job-definition/([A-Za-z0-9_-]+):\d+$`)
+
+// resolveJobDefinition returns the ARN and name of the job definition to
+// submit. An explicit arn is validated and used as is; otherwise the latest
+// active revision of the rendered definition is looked up.
+func (app *App) resolveJobDefinition(ctx context.Context, client batchAPI, arn string) (string, string, error) {
+	if arn != "" {
+		m := jobDefinitionArnPattern.FindStringSubmatch(arn)
+		if m == nil {
+			return "", "", fmt.Errorf("invalid job definition ARN %q (expected arn:<partition>:batch:<region>:<account>:job-definition/<name>:<revision>)", arn)
+		}
+		return arn, m[1], nil
+	}
+
+	name, err := app.jobDefinitionName(ctx)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Fetch the latest active revision ARN
+	out, err := client.DescribeJobDefinitions(ctx, &batch.DescribeJobDefinitionsInput{
+		JobDefinitionName: aws.String(name),
+		Status:            aws.String("ACTIVE"),
+	})
+	if err != nil {
+		return "", "", withHint(fmt.Errorf("failed to describe job definitions: %w", err), opDescribe)
+	}
+	if len(out.JobDefinitions) == 0 {
+		return "", "", fmt.Errorf("no active job definition found for %q", name)
+	}
+	latest := pickLatestRevision(out.JobDefinitions)
+	return aws.ToString(latest.JobDefinitionArn), name, nil
+}
 
-	ticker := time.NewTicker(10 * time.Second)
+// commandFromFile returns a container command that runs the script in path
+// with shell.
+func commandFromFile(path, shell string) ([]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read command file: %w", err)
+	}
+	if strings.TrimSpace(string(b)) == "" {
+		return nil, fmt.Errorf("command file %s is empty", path)
+	}
+	if shell == "" {
+		shell = "sh"
+	}
+	return []string{shell, "-c", string(b)}, nil
+}
+
+// mergeParameters merges parameter maps; later maps win.
+func mergeParameters(layers ...map[string]string) map[string]string {
+	merged := make(map[string]string)
+	for _, l := range layers {
+		maps.Copy(merged, l)
+	}
+	return merged
+}
+
+// readParameterFile reads a flat map of parameters from a YAML or JSON file.
+func readParameterFile(path string) (map[string]string, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read parameter file: %w", err)
+	}
+	var params map[string]string
+	if err := yaml.Unmarshal(b, &params); err != nil {
+		return nil, fmt.Errorf("failed to parse parameter file %s: %w", path, err)
+	}
+	return params, nil
+}
+
+// parametersFromJob returns the parameters the given job was submitted with.
+func parametersFromJob(ctx context.Context, client batchAPI, jobID string) (map[string]string, error) {
+	out, err := client.DescribeJobs(ctx, &batch.DescribeJobsInput{
+		Jobs: []string{jobID},
+	})
+	if err != nil {
+		return nil, withHint(fmt.Errorf("failed to describe job %s: %w", jobID, err), opDescribe)
+	}
+	if len(out.Jobs) == 0 {
+		return nil, fmt.Errorf("job %s not found", jobID)
+	}
+
+	return out.Jobs[0].Parameters, nil
+}
+
+// waitForJob polls the job until it finishes, writing progress to w. When
+// logs is non-nil, new log lines are printed on every poll. It returns the
+// final job detail, and an error if the job failed.
+func (app *App) waitForJob(ctx context.Context, client batchAPI, jobID string, w io.Writer, logs *logPoller) (*batchTypes.JobDetail, error) {
+	fmt.Fprintf(w, "Waiting for job %s...\n", jobID)
+
+	ticker := time.NewTicker(jobPollInterval)
 	defer ticker.Stop()
 
 	var lastStatus batchTypes.JobStatus
 	for {
 		select {
 		case <-ctx.Done():
-			return ctx.Err()
+			return nil, ctx.Err()
 		case <-ticker.C:
 			out, err := client.DescribeJobs(ctx, &batch.DescribeJobsInput{
 				Jobs: []string{jobID},
 			})
 			if err != nil {
-				return fmt.Errorf("failed to describe job: %w", err)
+				return nil, fmt.Errorf("failed to describe job: %w", err)
 			}
 			if len(out.Jobs) == 0 {
-				return fmt.Errorf("job %s not found", jobID)
+				return nil, fmt.Errorf("job %s not found", jobID)
 			}
 
 			job := out.Jobs[0]
 			if job.Status != lastStatus {
-				fmt.Printf("  %s\n", job.Status)
+				fmt.Fprintf(w, "  %s\n", job.Status)
 				lastStatus = job.Status
 			}
+			if logs != nil {
+				logs.poll(ctx, job)
+			}
 
 			switch job.Status {
 			case batchTypes.JobStatusSucceeded:
-				fmt.Println("Job succeeded.")
-				return nil
+				fmt.Fprintln(w, "Job succeeded.")
+				return &job, nil
 			case batchTypes.JobStatusFailed:
 				reason := aws.ToString(job.StatusReason)
-				return fmt.Errorf("job failed: %s", reason)
+				return &job, fmt.Errorf("job failed: %s", reason)
 			}
 		}
 	}
 }
+
+// pollLogsLines is the maximum number of new log lines printed per poll by
+// run --wait --poll-logs.
+const pollLogsLines = 5
+
+// logPoller prints snapshots of a job's newest log lines while waiting.
+type logPoller struct {
+	client logsAPI
+	w      io.Writer
+	// token is the forward token of the last read, nil before the first read.
+	token *string
+}
+
+// poll prints up to pollLogsLines of the events written since the last poll.
+// It does nothing until the job has a log stream.
+func (p *logPoller) poll(ctx context.Context, job batchTypes.JobDetail) {
+	logGroup, logStream, err := extractLogInfo(job)
+	if err != nil {
+		return
+	}
+	var events []cwlTypes.OutputLogEvent
+	input := &cloudwatchlogs.GetLogEventsInput{
+		LogGroupName:  aws.String(logGroup),
+		LogStreamName: aws.String(logStream),
+		StartFromHead: aws.Bool(true),
+		NextToken:     p.token,
+	}
+	for {
+		out, err := p.client.GetLogEvents(ctx, input)
+		if err != nil {
+			// The stream may not be created yet right after the job starts.
+			var notFound *cwlTypes.ResourceNotFoundException
+			if !errors.As(err, &notFound) {
+				fmt.Fprintf(os.Stderr, "failed to get log events: %s\n", err)
+			}
+			return
+		}
+		events = append(events, out.Events...)
+		if aws.ToString(out.NextForwardToken) == aws.ToString(input.NextToken) {
+			break
+		}
+		input.NextToken = out.NextForwardToken
+		p.token = out.NextForwardToken
+		if len(out.Events) == 0 {
+			break
+		}
+	}
+
+	if len(events) > pollLogsLines {
+		fmt.Fprintf(p.w, "  | ... %d earlier lines\n", len(events)-pollLogsLines)
+		events = events[len(events)-pollLogsLines:]
+	}
+	for _, e := range events {
+		fmt.Fprintf(p.w, "  | %s\n", aws.ToString(e.Message))
+	}
+}
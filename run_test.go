@@ -0,0 +1,647 @@
+package batcha
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs"
+	cwlTypes "github.com/aws/aws-sdk-go-v2/service/cloudwatchlogs/types"
+	"github.com/aws/smithy-go"
+)
+
+func TestRun_ParametersFromJob(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "rerun-job", "type": "container"}`)
+
+	var submitted map[string]string
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{
+				JobDefinitions: []batchTypes.JobDefinition{{
+					JobDefinitionArn:  aws.String("arn:aws:batch:us-east-1:123456789012:job-definition/rerun-job:3"),
+					JobDefinitionName: aws.String("rerun-job"),
+					Revision:          aws.Int32(3),
+				}},
+			}, nil
+		},
+		describeJobs: func(in *batch.DescribeJobsInput) (*batch.DescribeJobsOutput, error) {
+			if in.Jobs[0] != "old-job" {
+				return &batch.DescribeJobsOutput{}, nil
+			}
+			return &batch.DescribeJobsOutput{
+				Jobs: []batchTypes.JobDetail{{
+					JobId:      aws.String("old-job"),
+					Parameters: map[string]string{"input": "s3://bucket/a.csv", "mode": "full"},
+				}},
+			}, nil
+		},
+		submitJob: func(in *batch.SubmitJobInput) (*batch.SubmitJobOutput, error) {
+			submitted = in.Parameters
+			return &batch.SubmitJobOutput{JobId: aws.String("new-job"), JobName: in.JobName}, nil
+		},
+	}}
+
+	err := app.Run(context.Background(), RunOption{
+		JobQueue:          "queue",
+		Parameters:        map[string]string{"mode": "incremental"},
+		ParametersFromJob: "old-job",
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	want := map[string]string{"input": "s3://bucket/a.csv", "mode": "incremental"}
+	if !maps.Equal(submitted, want) {
+		t.Errorf("submitted parameters = %v, want %v", submitted, want)
+	}
+
+	err = app.Run(context.Background(), RunOption{JobQueue: "queue", ParametersFromJob: "missing-job"})
+	if err == nil || !strings.Contains(err.Error(), "missing-job not found") {
+		t.Errorf("expected not found error, got: %v", err)
+	}
+}
+
+func TestRun_ParameterPrecedence(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "param-job", "type": "container"}`)
+	app.config.DefaultParameters = map[string]string{"env": "production", "mode": "full", "date": "today"}
+
+	paramFile := filepath.Join(t.TempDir(), "params.yml")
+	if err := os.WriteFile(paramFile, []byte("mode: incremental\ndate: \"2026-01-01\"\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var submitted map[string]string
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{
+				JobDefinitions: []batchTypes.JobDefinition{{JobDefinitionArn: aws.String("arn"), Revision: aws.Int32(1)}},
+			}, nil
+		},
+		submitJob: func(in *batch.SubmitJobInput) (*batch.SubmitJobOutput, error) {
+			submitted = in.Parameters
+			return &batch.SubmitJobOutput{JobId: aws.String("job"), JobName: in.JobName}, nil
+		},
+	}}
+
+	err := app.Run(context.Background(), RunOption{
+		JobQueue:      "queue",
+		ParameterFile: paramFile,
+		Parameters:    map[string]string{"date": "2026-02-02"},
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	// config < file < flags
+	want := map[string]string{"env": "production", "mode": "incremental", "date": "2026-02-02"}
+	if !maps.Equal(submitted, want) {
+		t.Errorf("submitted parameters = %v, want %v", submitted, want)
+	}
+}
+
+func TestParametersFromJob_DescribeError(t *testing.T) {
+	client := &fakeBatchClient{
+		describeJobs: func(*batch.DescribeJobsInput) (*batch.DescribeJobsOutput, error) {
+			return nil, fmt.Errorf("boom")
+		},
+	}
+	_, err := parametersFromJob(context.Background(), client, "old-job")
+	if err == nil || !strings.Contains(err.Error(), "failed to describe job old-job") {
+		t.Errorf("expected describe error, got: %v", err)
+	}
+}
+
+func TestRun_OutputJSON(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "json-job", "type": "container"}`)
+	arn := "arn:aws:batch:us-east-1:123456789012:job-definition/json-job:2"
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{
+				JobDefinitions: []batchTypes.JobDefinition{{JobDefinitionArn: aws.String(arn), Revision: aws.Int32(2)}},
+			}, nil
+		},
+		submitJob: func(in *batch.SubmitJobInput) (*batch.SubmitJobOutput, error) {
+			return &batch.SubmitJobOutput{JobId: aws.String("job-1"), JobName: in.JobName}, nil
+		},
+		describeJobs: func(*batch.DescribeJobsInput) (*batch.DescribeJobsOutput, error) {
+			return &batch.DescribeJobsOutput{Jobs: []batchTypes.JobDetail{{
+				JobId:        aws.String("job-1"),
+				Status:       batchTypes.JobStatusFailed,
+				StatusReason: aws.String("Essential container in task exited"),
+				Container:    &batchTypes.ContainerDetail{ExitCode: aws.Int32(3)},
+			}}}, nil
+		},
+	}}
+
+	out := captureStdout(t, func() {
+		if err := app.Run(context.Background(), RunOption{JobQueue: "queue", Output: "json"}); err != nil {
+			t.Errorf("Run failed: %v", err)
+		}
+	})
+	var res runResult
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	want := runResult{JobName: "json-job", JobID: "job-1", JobQueue: "queue", JobDefinitionArn: arn}
+	if res != want {
+		t.Errorf("result = %+v, want %+v", res, want)
+	}
+
+	defer func(d time.Duration) { jobPollInterval = d }(jobPollInterval)
+	jobPollInterval = time.Millisecond
+	var runErr error
+	out = captureStdout(t, func() {
+		runErr = app.Run(context.Background(), RunOption{JobQueue: "queue", Output: "json", Wait: true})
+	})
+	if runErr == nil || !strings.Contains(runErr.Error(), "job failed") {
+		t.Errorf("expected job failed error, got: %v", runErr)
+	}
+	res = runResult{}
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("output is not JSON: %v\n%s", err, out)
+	}
+	if res.Status != "FAILED" || res.ExitCode == nil || *res.ExitCode != 3 {
+		t.Errorf("status = %q, exitCode = %v, want FAILED, 3", res.Status, res.ExitCode)
+	}
+}
+
+// captureStdout returns what fn writes to os.Stdout.
+func captureStdout(t *testing.T, fn func()) string {
+	t.Helper()
+	return captureFile(t, &os.Stdout, fn)
+}
+
+// captureStderr returns what fn writes to os.Stderr.
+func captureStderr(t *testing.T, fn func()) string {
+	t.Helper()
+	return captureFile(t, &os.Stderr, fn)
+}
+
+func captureFile(t *testing.T, f **os.File, fn func()) string {
+	t.Helper()
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	orig := *f
+	*f = w
+	defer func() { *f = orig }()
+
+	done := make(chan string)
+	go func() {
+		b, _ := io.ReadAll(r)
+		done <- string(b)
+	}()
+	fn()
+	w.Close()
+	return <-done
+}
+
+func TestRun_WaitPollLogs(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "poll-job", "type": "container"}`)
+	defer func(d time.Duration) { jobPollInterval = d }(jobPollInterval)
+	jobPollInterval = time.Millisecond
+
+	polls := 0
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{
+				JobDefinitions: []batchTypes.JobDefinition{{JobDefinitionArn: aws.String("arn"), Revision: aws.Int32(1)}},
+			}, nil
+		},
+		submitJob: func(in *batch.SubmitJobInput) (*batch.SubmitJobOutput, error) {
+			return &batch.SubmitJobOutput{JobId: aws.String("job-1"), JobName: in.JobName}, nil
+		},
+		describeJobs: func(*batch.DescribeJobsInput) (*batch.DescribeJobsOutput, error) {
+			polls++
+			job := batchTypes.JobDetail{JobId: aws.String("job-1"), Status: batchTypes.JobStatusRunnable}
+			switch {
+			case polls == 2:
+				job.Status = batchTypes.JobStatusRunning
+				job.Container = &batchTypes.ContainerDetail{LogStreamName: aws.String("poll-job/default/abc")}
+			case polls > 2:
+				job.Status = batchTypes.JobStatusSucceeded
+				job.Container = &batchTypes.ContainerDetail{LogStreamName: aws.String("poll-job/default/abc")}
+			}
+			return &batch.DescribeJobsOutput{Jobs: []batchTypes.JobDetail{job}}, nil
+		},
+	}}
+	streamCreated := false
+	app.logsClient = &fakeLogsClient{
+		getLogEvents: func(in *cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error) {
+			if !streamCreated {
+				// The first poll races the creation of the log stream.
+				streamCreated = true
+				return nil, &cwlTypes.ResourceNotFoundException{Message: aws.String("The specified log stream does not exist.")}
+			}
+			if in.NextToken == nil {
+				return &cloudwatchlogs.GetLogEventsOutput{
+					Events: []cwlTypes.OutputLogEvent{
+						{Message: aws.String("step 1")},
+						{Message: aws.String("step 2")},
+					},
+					NextForwardToken: aws.String("f1"),
+				}, nil
+			}
+			return &cloudwatchlogs.GetLogEventsOutput{NextForwardToken: in.NextToken}, nil
+		},
+	}
+
+	var out string
+	stderr := captureStderr(t, func() {
+		out = captureStdout(t, func() {
+			if err := app.Run(context.Background(), RunOption{JobQueue: "queue", Wait: true, PollLogs: true}); err != nil {
+				t.Errorf("Run failed: %v", err)
+			}
+		})
+	})
+	if strings.Contains(stderr, "failed to get log events") {
+		t.Errorf("a log stream that does not exist yet should not be reported:\n%s", stderr)
+	}
+	if strings.Count(out, "  | step 1\n") != 1 || !strings.Contains(out, "  | step 2\n") {
+		t.Errorf("expected each log line once during the wait, got:\n%s", out)
+	}
+	if strings.Index(out, "step 2") > strings.Index(out, "Job succeeded.") {
+		t.Errorf("log lines should appear before completion, got:\n%s", out)
+	}
+}
+
+func TestLogPoller_ReportsOtherErrors(t *testing.T) {
+	poller := &logPoller{
+		client: &fakeLogsClient{
+			getLogEvents: func(*cloudwatchlogs.GetLogEventsInput) (*cloudwatchlogs.GetLogEventsOutput, error) {
+				return nil, &smithy.GenericAPIError{Code: "AccessDeniedException", Message: "not authorized"}
+			},
+		},
+		w: io.Discard,
+	}
+	job := batchTypes.JobDetail{Container: &batchTypes.ContainerDetail{LogStreamName: aws.String("poll-job/default/abc")}}
+	stderr := captureStderr(t, func() { poller.poll(context.Background(), job) })
+	if !strings.Contains(stderr, "failed to get log events: ") || !strings.Contains(stderr, "AccessDeniedException") {
+		t.Errorf("expected the access error on stderr, got: %q", stderr)
+	}
+}
+
+func TestRun_PollLogsRequiresWait(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "poll-job", "type": "container"}`)
+	err := app.Run(context.Background(), RunOption{JobQueue: "queue", PollLogs: true})
+	if err == nil || !strings.Contains(err.Error(), "--poll-logs requires --wait") {
+		t.Errorf("expected --poll-logs without --wait to be rejected, got: %v", err)
+	}
+}
+
+func TestRun_JobDefinitionArn(t *testing.T) {
+	// The template is never rendered when an ARN is given.
+	app := verifyApp(t, `{"jobDefinitionName": "{{ must_env "BATCHA_TEST_UNSET" }}"}`)
+	arn := "arn:aws:batch:us-east-1:123456789012:job-definition/pinned-job:7"
+
+	var submitted *batch.SubmitJobInput
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		submitJob: func(in *batch.SubmitJobInput) (*batch.SubmitJobOutput, error) {
+			submitted = in
+			return &batch.SubmitJobOutput{JobId: aws.String("job"), JobName: in.JobName}, nil
+		},
+	}}
+
+	if err := app.Run(context.Background(), RunOption{JobQueue: "queue", JobDefinitionArn: arn}); err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if aws.ToString(submitted.JobDefinition) != arn {
+		t.Errorf("JobDefinition = %s, want %s", aws.ToString(submitted.JobDefinition), arn)
+	}
+	if aws.ToString(submitted.JobName) != "pinned-job" {
+		t.Errorf("JobName = %s, want pinned-job", aws.ToString(submitted.JobName))
+	}
+
+	err := app.Run(context.Background(), RunOption{JobQueue: "queue", JobDefinitionArn: "pinned-job:7"})
+	if err == nil || !strings.Contains(err.Error(), "invalid job definition ARN") {
+		t.Errorf("expected invalid ARN error, got: %v", err)
+	}
+}
+
+func TestCommandFromFile(t *testing.T) {
+	dir := t.TempDir()
+	script := "set -eu\necho \"it's $HOME\"\n"
+	path := filepath.Join(dir, "script.sh")
+	if err := os.WriteFile(path, []byte(script), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := commandFromFile(path, "")
+	if err != nil {
+		t.Fatalf("commandFromFile failed: %v", err)
+	}
+	if want := []string{"sh", "-c", script}; !slices.Equal(got, want) {
+		t.Errorf("command = %q, want %q", got, want)
+	}
+	if got, _ := commandFromFile(path, "bash"); got[0] != "bash" {
+		t.Errorf("shell = %q, want bash", got[0])
+	}
+
+	empty := filepath.Join(dir, "empty.sh")
+	if err := os.WriteFile(empty, []byte(" \n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := commandFromFile(empty, ""); err == nil || !strings.Contains(err.Error(), "is empty") {
+		t.Errorf("expected empty file error, got: %v", err)
+	}
+	if _, err := commandFromFile(filepath.Join(dir, "missing.sh"), ""); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+func TestJobDefinitionArnPattern(t *testing.T) {
+	tests := []struct {
+		arn  string
+		name string // empty when the ARN is invalid
+	}{
+		{"arn:aws:batch:us-east-1:123456789012:job-definition/my-job:3", "my-job"},
+		{"arn:aws-us-gov:batch:us-gov-west-1:123456789012:job-definition/gov-job:1", "gov-job"},
+		{"arn:aws-cn:batch:cn-northwest-1:123456789012:job-definition/cn_job:12", "cn_job"},
+		{"arn:aws:batch:us-east-1:123456789012:job-definition/my-job", ""},
+		{"arn:aws:ecs:us-east-1:123456789012:task-definition/my-job:3", ""},
+	}
+	for _, tt := range tests {
+		m := jobDefinitionArnPattern.FindStringSubmatch(tt.arn)
+		got := ""
+		if m != nil {
+			got = m[1]
+		}
+		if got != tt.name {
+			t.Errorf("jobDefinitionArnPattern(%q) name = %q, want %q", tt.arn, got, tt.name)
+		}
+	}
+}
+
+func TestGenerateJobName(t *testing.T) {
+	now := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	pattern := regexp.MustCompile(`^myjob-20240102-150405-[0-9a-f]{4}$`)
+	name := generateJobName("myjob", now)
+	if !pattern.MatchString(name) {
+		t.Errorf("generateJobName = %q, want match %s", name, pattern)
+	}
+
+	long := generateJobName(strings.Repeat("a", 200), now)
+	if len(long) != maxJobNameLength {
+		t.Errorf("len = %d, want %d", len(long), maxJobNameLength)
+	}
+	if !regexp.MustCompile(`^a+-20240102-150405-[0-9a-f]{4}$`).MatchString(long) {
+		t.Errorf("truncated name lost its suffix: %q", long)
+	}
+}
+
+func TestRun_JobNamePrecedence(t *testing.T) {
+	t.Setenv("BATCHA_TEST_STAGE", "prod")
+	tests := []struct {
+		name           string
+		defaultJobName string
+		flag           string
+		want           string
+	}{
+		{name: "definition name", want: "named-job"},
+		{name: "config", defaultJobName: `nightly-{{ env "BATCHA_TEST_STAGE" }}`, want: "nightly-prod"},
+		{name: "flag wins", defaultJobName: "nightly", flag: "adhoc", want: "adhoc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			app := verifyApp(t, `{"jobDefinitionName": "named-job", "type": "container"}`)
+			app.config.DefaultJobName = tt.defaultJobName
+			var submitted string
+			app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+				describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+					return &batch.DescribeJobDefinitionsOutput{JobDefinitions: []batchTypes.JobDefinition{{
+						JobDefinitionArn:  aws.String("arn:aws:batch:us-east-1:123456789012:job-definition/named-job:1"),
+						JobDefinitionName: aws.String("named-job"),
+						Revision:          aws.Int32(1),
+					}}}, nil
+				},
+				submitJob: func(in *batch.SubmitJobInput) (*batch.SubmitJobOutput, error) {
+					submitted = aws.ToString(in.JobName)
+					return &batch.SubmitJobOutput{JobId: aws.String("id"), JobName: in.JobName}, nil
+				},
+			}}
+			var err error
+			captureStdout(t, func() {
+				err = app.Run(context.Background(), RunOption{JobQueue: "queue", JobName: tt.flag})
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if submitted != tt.want {
+				t.Errorf("job name = %q, want %q", submitted, tt.want)
+			}
+		})
+	}
+}
+
+func TestRun_DiffBlocksStaleDefinition(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "drift-job", "type": "container", "containerProperties": {"image": "busybox:2"}}`)
+	submitted := 0
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{JobDefinitions: []batchTypes.JobDefinition{{
+				JobDefinitionArn:    aws.String("arn:aws:batch:us-east-1:123456789012:job-definition/drift-job:1"),
+				JobDefinitionName:   aws.String("drift-job"),
+				Revision:            aws.Int32(1),
+				Status:              aws.String("ACTIVE"),
+				Type:                aws.String("container"),
+				ContainerProperties: &batchTypes.ContainerProperties{Image: aws.String("busybox:1")},
+			}}}, nil
+		},
+		submitJob: func(in *batch.SubmitJobInput) (*batch.SubmitJobOutput, error) {
+			submitted++
+			return &batch.SubmitJobOutput{JobId: aws.String("id"), JobName: in.JobName}, nil
+		},
+	}}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Run(context.Background(), RunOption{JobQueue: "queue", Diff: true})
+	})
+	if err == nil || !strings.Contains(err.Error(), "differs from the active job definition") {
+		t.Fatalf("expected drift error, got %v", err)
+	}
+	if submitted != 0 {
+		t.Error("job was submitted despite differences")
+	}
+	if !strings.Contains(out, `+    "Image": "busybox:2"`) {
+		t.Errorf("expected the diff to be printed:\n%s", out)
+	}
+
+	captureStdout(t, func() {
+		err = app.Run(context.Background(), RunOption{JobQueue: "queue", Diff: true, Force: true})
+	})
+	if err != nil {
+		t.Fatalf("Run with --force failed: %v", err)
+	}
+	if submitted != 1 {
+		t.Errorf("submitted %d jobs with --force, want 1", submitted)
+	}
+
+	if err := app.Run(context.Background(), RunOption{JobQueue: "queue", Force: true}); err == nil {
+		t.Error("expected --force without --diff to fail")
+	}
+}
+
+func TestRun_TimeoutSeconds(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "timeout-job", "type": "container"}`)
+	var submitted *batch.SubmitJobInput
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{
+				JobDefinitions: []batchTypes.JobDefinition{{JobDefinitionArn: aws.String("arn"), Revision: aws.Int32(1)}},
+			}, nil
+		},
+		submitJob: func(in *batch.SubmitJobInput) (*batch.SubmitJobOutput, error) {
+			submitted = in
+			return &batch.SubmitJobOutput{JobId: aws.String("job-1"), JobName: in.JobName}, nil
+		},
+	}}
+
+	var err error
+	captureStdout(t, func() {
+		err = app.Run(context.Background(), RunOption{JobQueue: "queue", TimeoutSeconds: 900})
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if submitted.Timeout == nil || aws.ToInt32(submitted.Timeout.AttemptDurationSeconds) != 900 {
+		t.Errorf("Timeout = %+v, want 900 seconds", submitted.Timeout)
+	}
+
+	submitted = nil
+	captureStdout(t, func() {
+		err = app.Run(context.Background(), RunOption{JobQueue: "queue"})
+	})
+	if err != nil || submitted.Timeout != nil {
+		t.Errorf("expected no timeout override by default, got %+v (%v)", submitted.Timeout, err)
+	}
+
+	err = app.Run(context.Background(), RunOption{JobQueue: "queue", TimeoutSeconds: 30})
+	if err == nil || !strings.Contains(err.Error(), "--timeout-seconds must be at least 60") {
+		t.Errorf("expected a minimum timeout error, got: %v", err)
+	}
+}
+
+func TestRun_ShareIdentifier(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "share-job", "type": "container"}`)
+	var submitted *batch.SubmitJobInput
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{
+				JobDefinitions: []batchTypes.JobDefinition{{JobDefinitionArn: aws.String("arn"), Revision: aws.Int32(1)}},
+			}, nil
+		},
+		submitJob: func(in *batch.SubmitJobInput) (*batch.SubmitJobOutput, error) {
+			submitted = in
+			if in.ShareIdentifier == nil {
+				return nil, errors.New("ClientException: Share identifier is required")
+			}
+			return &batch.SubmitJobOutput{JobId: aws.String("job-1"), JobName: in.JobName}, nil
+		},
+		describeJobQueues: func(in *batch.DescribeJobQueuesInput) (*batch.DescribeJobQueuesOutput, error) {
+			return &batch.DescribeJobQueuesOutput{JobQueues: []batchTypes.JobQueueDetail{{
+				JobQueueName:        aws.String(in.JobQueues[0]),
+				SchedulingPolicyArn: aws.String("arn:aws:batch:us-east-1:123456789012:scheduling-policy/fair"),
+			}}}, nil
+		},
+	}}
+
+	var err error
+	captureStdout(t, func() {
+		err = app.Run(context.Background(), RunOption{JobQueue: "fair-queue", ShareIdentifier: "teamA"})
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if aws.ToString(submitted.ShareIdentifier) != "teamA" {
+		t.Errorf("ShareIdentifier = %v, want teamA", submitted.ShareIdentifier)
+	}
+
+	err = app.Run(context.Background(), RunOption{JobQueue: "fair-queue"})
+	if err == nil || !strings.Contains(err.Error(), `job queue "fair-queue" uses the fair-share scheduling policy`) ||
+		!strings.Contains(err.Error(), "pass --share-identifier") {
+		t.Errorf("expected a missing share identifier error, got: %v", err)
+	}
+}
+
+func TestRun_RetryFailed(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "retry-job", "type": "container"}`)
+	jobs := map[string]batchTypes.JobDetail{
+		"failed-1": {
+			JobId:         aws.String("failed-1"),
+			JobName:       aws.String("nightly"),
+			JobQueue:      aws.String("arn:aws:batch:us-east-1:123456789012:job-queue/main"),
+			JobDefinition: aws.String("arn:aws:batch:us-east-1:123456789012:job-definition/retry-job:7"),
+			Status:        batchTypes.JobStatusFailed,
+			Parameters:    map[string]string{"date": "2024-01-02"},
+			Tags:          map[string]string{"team": "data", "aws:batch:job": "x"},
+			Container: &batchTypes.ContainerDetail{
+				Command: []string{"run.sh", "--date", "2024-01-02"},
+				Environment: []batchTypes.KeyValuePair{
+					{Name: aws.String("STAGE"), Value: aws.String("prod")},
+					{Name: aws.String("AWS_BATCH_JOB_ID"), Value: aws.String("failed-1")},
+				},
+			},
+		},
+		"ok-1": {JobId: aws.String("ok-1"), Status: batchTypes.JobStatusSucceeded},
+	}
+	var submitted *batch.SubmitJobInput
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobs: func(in *batch.DescribeJobsInput) (*batch.DescribeJobsOutput, error) {
+			out := &batch.DescribeJobsOutput{}
+			if j, ok := jobs[in.Jobs[0]]; ok {
+				out.Jobs = []batchTypes.JobDetail{j}
+			}
+			return out, nil
+		},
+		submitJob: func(in *batch.SubmitJobInput) (*batch.SubmitJobOutput, error) {
+			submitted = in
+			return &batch.SubmitJobOutput{JobId: aws.String("new-1"), JobName: in.JobName}, nil
+		},
+	}}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Run(context.Background(), RunOption{RetryFailed: "failed-1"})
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !strings.Contains(out, "Retrying failed job failed-1") || !strings.Contains(out, "Submitted job: nightly (ID: new-1)") {
+		t.Errorf("expected both job IDs, got:\n%s", out)
+	}
+	if aws.ToString(submitted.JobDefinition) != "arn:aws:batch:us-east-1:123456789012:job-definition/retry-job:7" ||
+		aws.ToString(submitted.JobQueue) != "arn:aws:batch:us-east-1:123456789012:job-queue/main" {
+		t.Errorf("expected the failed job's revision and queue, got %s on %s", aws.ToString(submitted.JobDefinition), aws.ToString(submitted.JobQueue))
+	}
+	if submitted.Parameters["date"] != "2024-01-02" {
+		t.Errorf("Parameters = %v", submitted.Parameters)
+	}
+	if !maps.Equal(submitted.Tags, map[string]string{"team": "data"}) {
+		t.Errorf("Tags = %v, want reserved aws: tags dropped", submitted.Tags)
+	}
+	co := submitted.ContainerOverrides
+	if co == nil || !slices.Equal(co.Command, []string{"run.sh", "--date", "2024-01-02"}) ||
+		len(co.Environment) != 1 || aws.ToString(co.Environment[0].Name) != "STAGE" {
+		t.Errorf("ContainerOverrides = %+v, want the command and non-AWS_BATCH environment", co)
+	}
+
+	if err := app.Run(context.Background(), RunOption{RetryFailed: "ok-1"}); err == nil || !strings.Contains(err.Error(), "is SUCCEEDED, not FAILED") {
+		t.Errorf("expected an error for a job that did not fail, got: %v", err)
+	}
+	if err := app.Run(context.Background(), RunOption{RetryFailed: "missing"}); err == nil || !strings.Contains(err.Error(), "job missing not found") {
+		t.Errorf("expected a not found error, got: %v", err)
+	}
+}
@@ -0,0 +1,118 @@
+package batcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+)
+
+// runBatchEntry is one submission of run --from-status-file. The file is a
+// JSON array of entries; run prints the same array back with jobId or error
+// filled in, so a printed status file can be fed back to retry the failures.
+type runBatchEntry struct {
+	JobName    string            `json:"jobName,omitempty"`
+	Parameters map[string]string `json:"parameters,omitempty"`
+
+	// JobID is set once the entry was submitted; such entries are skipped.
+	JobID string `json:"jobId,omitempty"`
+	// Error is the submission error of the last attempt.
+	Error string `json:"error,omitempty"`
+}
+
+// defaultRunConcurrency is the number of submissions in flight with
+// --from-status-file unless --concurrency is set.
+const defaultRunConcurrency = 1
+
+func readStatusFile(path string) ([]runBatchEntry, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read status file: %w", err)
+	}
+	var entries []runBatchEntry
+	if err := json.Unmarshal(b, &entries); err != nil {
+		return nil, fmt.Errorf("failed to parse status file %s: %w", path, err)
+	}
+	return entries, nil
+}
+
+// runBatch submits every entry of opt.FromStatusFile that has no jobId yet,
+// based on base. Entry parameters override the merged run parameters; an
+// entry without jobName uses baseName. A failed submission is recorded and
+// the others continue.
+func runBatch(ctx context.Context, client batchAPI, base *batch.SubmitJobInput, baseName string, opt RunOption) error {
+	entries, err := readStatusFile(opt.FromStatusFile)
+	if err != nil {
+		return err
+	}
+
+	concurrency := opt.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultRunConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+
+	var wg sync.WaitGroup
+	for i := range entries {
+		e := &entries[i]
+		if e.JobID != "" {
+			continue
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				e.Error = ctx.Err().Error()
+				return
+			}
+			defer func() { <-sem }()
+
+			name := e.JobName
+			if name == "" {
+				name = baseName
+			}
+			if opt.GenerateName {
+				name = generateJobName(name, time.Now())
+			}
+			input := *base
+			input.JobName = aws.String(name)
+			if params := mergeParameters(base.Parameters, e.Parameters); len(params) > 0 {
+				input.Parameters = params
+			}
+
+			out, err := client.SubmitJob(ctx, &input)
+			if err != nil {
+				e.Error = withHint(fmt.Errorf("failed to submit job: %w", err), opSubmit).Error()
+				return
+			}
+			e.JobName = aws.ToString(out.JobName)
+			e.JobID = aws.ToString(out.JobId)
+			e.Error = ""
+		}()
+	}
+	wg.Wait()
+
+	b, err := json.MarshalIndent(entries, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal status file: %w", err)
+	}
+	fmt.Println(string(b))
+
+	failed := 0
+	for _, e := range entries {
+		if e.JobID == "" {
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d submission(s) failed", failed, len(entries))
+	}
+	return nil
+}
@@ -0,0 +1,86 @@
+package batcha
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+)
+
+func TestRun_FromStatusFile(t *testing.T) {
+	app := verifyApp(t, `{"jobDefinitionName": "etl", "type": "container"}`)
+	app.config.DefaultParameters = map[string]string{"env": "prod", "month": "none"}
+
+	var (
+		mu        sync.Mutex
+		submitted = map[string]map[string]string{}
+	)
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(*batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{JobDefinitions: []batchTypes.JobDefinition{{
+				JobDefinitionArn:  aws.String("arn:aws:batch:us-east-1:123456789012:job-definition/etl:1"),
+				JobDefinitionName: aws.String("etl"),
+				Revision:          aws.Int32(1),
+			}}}, nil
+		},
+		submitJob: func(in *batch.SubmitJobInput) (*batch.SubmitJobOutput, error) {
+			name := aws.ToString(in.JobName)
+			if name == "etl-bad" {
+				return nil, fmt.Errorf("ClientException: invalid parameters")
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			submitted[name] = in.Parameters
+			return &batch.SubmitJobOutput{JobId: aws.String("id-" + name), JobName: in.JobName}, nil
+		},
+	}}
+
+	path := filepath.Join(t.TempDir(), "status.json")
+	entries := `[
+		{"jobName": "etl-01", "parameters": {"month": "01"}},
+		{"jobName": "etl-bad"},
+		{"jobName": "etl-done", "jobId": "old-id"}
+	]`
+	if err := os.WriteFile(path, []byte(entries), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Run(context.Background(), RunOption{JobQueue: "queue", FromStatusFile: path, Concurrency: 2})
+	})
+	if err == nil || err.Error() != "1 of 3 submission(s) failed" {
+		t.Errorf("err = %v, want 1 of 3 failed", err)
+	}
+
+	var status []runBatchEntry
+	if err := json.Unmarshal([]byte(out), &status); err != nil {
+		t.Fatalf("output is not a status file: %v\n%s", err, out)
+	}
+	if len(status) != 3 {
+		t.Fatalf("status has %d entries, want 3", len(status))
+	}
+	if status[0].JobID != "id-etl-01" || status[0].Error != "" {
+		t.Errorf("entry 0 = %+v", status[0])
+	}
+	if status[1].JobID != "" || status[1].Error == "" {
+		t.Errorf("entry 1 should record the failure: %+v", status[1])
+	}
+	if status[2].JobID != "old-id" {
+		t.Errorf("entry 2 should be kept as submitted: %+v", status[2])
+	}
+
+	if len(submitted) != 1 {
+		t.Errorf("submitted %v, want only etl-01", submitted)
+	}
+	if got := submitted["etl-01"]; got["month"] != "01" || got["env"] != "prod" {
+		t.Errorf("etl-01 parameters = %v, want entry parameters over default_parameters", got)
+	}
+}
@@ -0,0 +1,61 @@
+package batcha
+
+import (
+	"context"
+	"fmt"
+	"text/template"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+)
+
+// ssmAPI is the subset of the SSM API used by the ssm plugin.
+type ssmAPI interface {
+	GetParameter(ctx context.Context, params *ssm.GetParameterInput, optFns ...func(*ssm.Options)) (*ssm.GetParameterOutput, error)
+}
+
+// ssmFuncMap returns the ssm and ssm_raw template functions. ssm decrypts
+// SecureString parameters; ssm_raw returns them as stored (the encrypted
+// blob). Values are cached for the lifetime of the FuncMap.
+func ssmFuncMap(ctx context.Context, client ssmAPI) template.FuncMap {
+	type key struct {
+		name    string
+		decrypt bool
+	}
+	cache := map[key]string{}
+	get := func(name string, decrypt bool) (string, error) {
+		k := key{name, decrypt}
+		if v, ok := cache[k]; ok {
+			return v, nil
+		}
+		v, err := getSSMParameter(ctx, client, name, decrypt)
+		if err != nil {
+			return "", err
+		}
+		cache[k] = v
+		return v, nil
+	}
+	return template.FuncMap{
+		"ssm": func(name string) (string, error) {
+			return get(name, true)
+		},
+		"ssm_raw": func(name string) (string, error) {
+			return get(name, false)
+		},
+	}
+}
+
+// getSSMParameter returns the value of an SSM parameter.
+func getSSMParameter(ctx context.Context, client ssmAPI, name string, decrypt bool) (string, error) {
+	out, err := client.GetParameter(ctx, &ssm.GetParameterInput{
+		Name:           aws.String(name),
+		WithDecryption: aws.Bool(decrypt),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get SSM parameter %s: %w", name, err)
+	}
+	if out.Parameter == nil {
+		return "", fmt.Errorf("SSM parameter %s has no value", name)
+	}
+	return aws.ToString(out.Parameter.Value), nil
+}
@@ -0,0 +1,65 @@
+package batcha
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/ssm"
+	ssmTypes "github.com/aws/aws-sdk-go-v2/service/ssm/types"
+	goconfig "github.com/kayac/go-config"
+)
+
+type fakeSSMClient struct {
+	params map[string]ssmTypes.Parameter
+	calls  int
+}
+
+func (c *fakeSSMClient) GetParameter(_ context.Context, in *ssm.GetParameterInput, _ ...func(*ssm.Options)) (*ssm.GetParameterOutput, error) {
+	c.calls++
+	p, ok := c.params[aws.ToString(in.Name)]
+	if !ok {
+		return nil, fmt.Errorf("ParameterNotFound")
+	}
+	if p.Type == ssmTypes.ParameterTypeSecureString && !aws.ToBool(in.WithDecryption) {
+		p.Value = aws.String("AQICAHencrypted")
+	}
+	return &ssm.GetParameterOutput{Parameter: &p}, nil
+}
+
+func TestSSMFuncMap(t *testing.T) {
+	client := &fakeSSMClient{params: map[string]ssmTypes.Parameter{
+		"/app/image":    {Type: ssmTypes.ParameterTypeString, Value: aws.String("busybox:latest")},
+		"/app/password": {Type: ssmTypes.ParameterTypeSecureString, Value: aws.String("s3cret")},
+	}}
+	loader := goconfig.New()
+	loader.Funcs(ssmFuncMap(context.Background(), client))
+
+	src := `{"image": "{{ ssm "/app/image" }}", "plain": "{{ ssm "/app/password" }}", "raw": "{{ ssm_raw "/app/password" }}", "again": "{{ ssm "/app/image" }}"}`
+	var got map[string]any
+	if err := loader.LoadWithEnvJSONBytes(&got, []byte(src)); err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]string{"image": "busybox:latest", "plain": "s3cret", "raw": "AQICAHencrypted", "again": "busybox:latest"}
+	for k, v := range want {
+		if got[k] != v {
+			t.Errorf("%s = %v, want %q", k, got[k], v)
+		}
+	}
+	if client.calls != 3 {
+		t.Errorf("GetParameter called %d times, want 3 (cached)", client.calls)
+	}
+}
+
+func TestSSMFuncMap_NotFound(t *testing.T) {
+	loader := goconfig.New()
+	loader.Funcs(ssmFuncMap(context.Background(), &fakeSSMClient{}))
+
+	var got map[string]any
+	err := loader.LoadWithEnvJSONBytes(&got, []byte(`{"v": "{{ ssm "/missing" }}"}`))
+	if err == nil || !strings.Contains(err.Error(), "/missing") {
+		t.Errorf("expected error mentioning the parameter, got %v", err)
+	}
+}
@@ -1,29 +1,156 @@
 package batcha
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"text/template"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
 )
 
-// Status shows the current state of the job definition on AWS.
-func (app *App) Status(ctx context.Context) error {
-	rendered, err := app.render(ctx)
-	if err != nil {
-		return err
+// StatusOption holds options for the status command.
+type StatusOption struct {
+	// Output is the output format: "text" (default), "wide", "json" or
+	// "env" (shell variable assignments).
+	Output string
+	// Template is a Go text/template executed with the StatusResult.
+	// It takes precedence over Output.
+	Template string
+	// Name is the job definition name to inspect. When set, the template is
+	// not rendered (--no-render).
+	Name string
+}
+
+// StatusResult is the state of the job definition on AWS.
+type StatusResult struct {
+	Region               string                      `json:"region"`
+	Name                 string                      `json:"name"`
+	ARN                  string                      `json:"arn,omitempty"`
+	Revision             int32                       `json:"revision,omitempty"`
+	Status               string                      `json:"status,omitempty"`
+	Type                 string                      `json:"type,omitempty"`
+	Image                string                      `json:"image,omitempty"`
+	ResourceRequirements []StatusResourceRequirement `json:"resourceRequirements,omitempty"`
+	ActiveRevisions      int                         `json:"activeRevisions"`
+
+	// Printed by --output wide.
+	Command          []string            `json:"command,omitempty"`
+	Environment      []StatusEnvironment `json:"environment,omitempty"`
+	JobRoleARN       string              `json:"jobRoleArn,omitempty"`
+	ExecutionRoleARN string              `json:"executionRoleArn,omitempty"`
+}
+
+// StatusEnvironment is a container environment variable in StatusResult.
+// Secrets are not included.
+type StatusEnvironment struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// StatusResourceRequirement is a resource requirement in StatusResult.
+type StatusResourceRequirement struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// Status shows the current state of the job definition on AWS in every
+// configured region.
+func (app *App) Status(ctx context.Context, opt StatusOption) error {
+	var tmpl *template.Template
+	if opt.Template != "" {
+		var err error
+		if tmpl, err = template.New("status").Parse(opt.Template); err != nil {
+			return fmt.Errorf("invalid --template: %w", err)
+		}
+	} else {
+		switch opt.Output {
+		case "", "text", "wide", "json":
+		case "env":
+			// Every region would assign the same variables.
+			if len(app.config.Regions) > 1 {
+				return fmt.Errorf("--output env supports a single region (the config has %d regions)", len(app.config.Regions))
+			}
+		default:
+			return fmt.Errorf("unknown output format %q (expected text, wide, json or env)", opt.Output)
+		}
+	}
+	if len(app.config.Regions) > 1 && (tmpl != nil || opt.Output == "json") {
+		return app.statusRegions(ctx, opt, tmpl)
 	}
-	converted := walkMap(rendered, toPascalCase)
+	return app.eachRegion(func(app *App) error {
+		res, err := app.status(ctx, opt.Name)
+		if err != nil {
+			return err
+		}
+		switch {
+		case tmpl != nil:
+			return executeStatusTemplate(tmpl, res)
+		case opt.Output == "json":
+			return printJSON(res)
+		case opt.Output == "env":
+			printStatusEnv(res)
+			return nil
+		}
+		printStatusText(res, opt.Output == "wide")
+		return nil
+	})
+}
 
-	name, _ := converted.(map[string]any)["JobDefinitionName"].(string)
-	if name == "" {
-		return fmt.Errorf("jobDefinitionName is required in job definition")
+// statusRegions prints the status of every region for machine-readable
+// output, without the "==> region" headers: one JSON array of the results, or
+// the template executed once per region. Each result carries its region.
+func (app *App) statusRegions(ctx context.Context, opt StatusOption, tmpl *template.Template) error {
+	results := []*StatusResult{} // printed as [] when every region failed
+	var errs []error
+	for _, rc := range app.config.Regions {
+		res, err := app.forRegion(rc.Region).status(ctx, opt.Name)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", rc.Region, err))
+			continue
+		}
+		results = append(results, res)
+	}
+	if tmpl == nil {
+		if err := printJSON(results); err != nil {
+			return err
+		}
+		return errors.Join(errs...)
+	}
+	for _, res := range results {
+		if err := executeStatusTemplate(tmpl, res); err != nil {
+			return err
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// executeStatusTemplate prints res with the --template, followed by a newline.
+func executeStatusTemplate(tmpl *template.Template, res *StatusResult) error {
+	if err := tmpl.Execute(os.Stdout, res); err != nil {
+		return fmt.Errorf("failed to execute --template: %w", err)
+	}
+	fmt.Println()
+	return nil
+}
+
+func (app *App) status(ctx context.Context, name string) (*StatusResult, error) {
+	name, err := app.definitionName(ctx, name)
+	if err != nil {
+		return nil, err
 	}
 
 	client, err := app.newBatchClient(ctx)
 	if err != nil {
-		return fmt.Errorf("failed to load AWS config: %w", err)
+		return nil, fmt.Errorf("failed to load AWS config: %w", err)
 	}
 
 	out, err := client.DescribeJobDefinitions(ctx, &batch.DescribeJobDefinitionsInput{
@@ -31,29 +158,167 @@ func (app *App) Status(ctx context.Context) error {
 		Status:            aws.String("ACTIVE"),
 	})
 	if err != nil {
-		return fmt.Errorf("failed to describe job definitions: %w", err)
+		return nil, fmt.Errorf("failed to describe job definitions: %w", err)
 	}
 
+	res := &StatusResult{Region: app.config.Region, Name: name, ActiveRevisions: len(out.JobDefinitions)}
 	if len(out.JobDefinitions) == 0 {
-		fmt.Printf("No active job definition found for %q.\n", name)
-		return nil
+		return res, nil
 	}
 
 	latest := pickLatestRevision(out.JobDefinitions)
+	res.ARN = aws.ToString(latest.JobDefinitionArn)
+	res.Revision = aws.ToInt32(latest.Revision)
+	res.Status = aws.ToString(latest.Status)
+	res.Type = aws.ToString(latest.Type)
+	if cp := latest.ContainerProperties; cp != nil {
+		res.Image = aws.ToString(cp.Image)
+		for _, r := range sortResourceRequirements(cp.ResourceRequirements) {
+			res.ResourceRequirements = append(res.ResourceRequirements, StatusResourceRequirement{
+				Type:  string(r.Type),
+				Value: aws.ToString(r.Value),
+			})
+		}
+		res.Command = cp.Command
+		for _, e := range cp.Environment {
+			res.Environment = append(res.Environment, StatusEnvironment{
+				Name:  aws.ToString(e.Name),
+				Value: aws.ToString(e.Value),
+			})
+		}
+		res.JobRoleARN = aws.ToString(cp.JobRoleArn)
+		res.ExecutionRoleARN = aws.ToString(cp.ExecutionRoleArn)
+	}
+	return res, nil
+}
 
-	fmt.Printf("Name:     %s\n", aws.ToString(latest.JobDefinitionName))
-	fmt.Printf("ARN:      %s\n", aws.ToString(latest.JobDefinitionArn))
-	fmt.Printf("Revision: %d\n", aws.ToInt32(latest.Revision))
-	fmt.Printf("Status:   %s\n", aws.ToString(latest.Status))
-	fmt.Printf("Type:     %s\n", aws.ToString(latest.Type))
+func printStatusText(res *StatusResult, wide bool) {
+	if res.ActiveRevisions == 0 {
+		fmt.Printf("No active job definition found for %q.\n", res.Name)
+		return
+	}
 
-	if cp := latest.ContainerProperties; cp != nil {
-		fmt.Printf("Image:    %s\n", aws.ToString(cp.Image))
-		for _, r := range cp.ResourceRequirements {
-			fmt.Printf("%-9s %s\n", string(r.Type)+":", aws.ToString(r.Value))
+	fmt.Printf("Name:     %s\n", res.Name)
+	fmt.Printf("ARN:      %s\n", res.ARN)
+	fmt.Printf("Revision: %d\n", res.Revision)
+	fmt.Printf("Status:   %s\n", res.Status)
+	fmt.Printf("Type:     %s\n", res.Type)
+
+	if res.Image != "" || len(res.ResourceRequirements) > 0 {
+		fmt.Printf("Image:    %s\n", res.Image)
+		for _, r := range res.ResourceRequirements {
+			fmt.Printf("%-9s %s\n", r.Type+":", r.Value)
 		}
 	}
 
-	fmt.Printf("Active revisions: %d\n", len(out.JobDefinitions))
-	return nil
+	fmt.Printf("Active revisions: %d\n", res.ActiveRevisions)
+
+	if wide {
+		printStatusWide(res)
+	}
+}
+
+// printStatusEnv prints res as shell variable assignments for
+// eval "$(batcha status --output env)". Every variable is printed, empty when
+// there is no active revision.
+func printStatusEnv(res *StatusResult) {
+	revision := ""
+	if res.ActiveRevisions > 0 {
+		revision = strconv.Itoa(int(res.Revision))
+	}
+	for _, kv := range [][2]string{
+		{"BATCHA_REGION", res.Region},
+		{"BATCHA_NAME", res.Name},
+		{"BATCHA_REVISION", revision},
+		{"BATCHA_ARN", res.ARN},
+		{"BATCHA_STATUS", res.Status},
+		{"BATCHA_IMAGE", res.Image},
+		{"BATCHA_ACTIVE_REVISIONS", strconv.Itoa(res.ActiveRevisions)},
+	} {
+		fmt.Printf("%s=%s\n", kv[0], shellQuote(kv[1]))
+	}
+}
+
+// shellSafePattern matches values that need no quoting in a POSIX shell.
+var shellSafePattern = regexp.MustCompile(`^[A-Za-z0-9_@%+=:,./-]+$`)
+
+// shellQuote quotes s for a POSIX shell. Values with special characters are
+// single-quoted; an embedded single quote ends the quoting, is escaped with
+// a backslash and starts it again.
+func shellQuote(s string) string {
+	if shellSafePattern.MatchString(s) {
+		return s
+	}
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// maxWideValueLength is the length at which --output wide truncates values.
+const maxWideValueLength = 80
+
+func printStatusWide(res *StatusResult) {
+	truncated := false
+	show := func(s string) string {
+		if t := truncate(s, maxWideValueLength); t != s {
+			truncated = true
+			return t
+		}
+		return s
+	}
+
+	args := make([]string, len(res.Command))
+	for i, a := range res.Command {
+		if a == "" || strings.ContainsAny(a, " \t\n\"'") {
+			a = strconv.Quote(a)
+		}
+		args[i] = a
+	}
+	fmt.Printf("Command:  %s\n", show(strings.Join(args, " ")))
+	if len(res.Environment) == 0 {
+		fmt.Println("Environment: (none)")
+	} else {
+		fmt.Println("Environment:")
+		for _, e := range res.Environment {
+			fmt.Printf("  %s=%s\n", e.Name, show(e.Value))
+		}
+	}
+	fmt.Printf("JobRoleArn:       %s\n", res.JobRoleARN)
+	fmt.Printf("ExecutionRoleArn: %s\n", res.ExecutionRoleARN)
+	if truncated {
+		fmt.Println("(long values truncated; use --output json for the full values)")
+	}
+}
+
+// truncate shortens s to at most n runes, ending with an ellipsis.
+func truncate(s string, n int) string {
+	r := []rune(s)
+	if len(r) <= n {
+		return s
+	}
+	return string(r[:n-1]) + "…"
+}
+
+// resourceTypeOrder is the display order of resource requirement types.
+// Unknown types are printed last in alphabetical order.
+var resourceTypeOrder = map[batchTypes.ResourceType]int{
+	batchTypes.ResourceTypeVcpu:   0,
+	batchTypes.ResourceTypeMemory: 1,
+	batchTypes.ResourceTypeGpu:    2,
+}
+
+// sortResourceRequirements returns a copy of reqs in a stable display order.
+func sortResourceRequirements(reqs []batchTypes.ResourceRequirement) []batchTypes.ResourceRequirement {
+	sorted := slices.Clone(reqs)
+	rank := func(t batchTypes.ResourceType) int {
+		if i, ok := resourceTypeOrder[t]; ok {
+			return i
+		}
+		return len(resourceTypeOrder)
+	}
+	slices.SortStableFunc(sorted, func(a, b batchTypes.ResourceRequirement) int {
+		if c := cmp.Compare(rank(a.Type), rank(b.Type)); c != 0 {
+			return c
+		}
+		return cmp.Compare(a.Type, b.Type)
+	})
+	return sorted
 }
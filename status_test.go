@@ -0,0 +1,249 @@
+package batcha
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/batch"
+	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
+)
+
+func TestSortResourceRequirements(t *testing.T) {
+	reqs := []batchTypes.ResourceRequirement{
+		{Type: batchTypes.ResourceTypeGpu, Value: aws.String("1")},
+		{Type: batchTypes.ResourceTypeMemory, Value: aws.String("2048")},
+		{Type: batchTypes.ResourceTypeVcpu, Value: aws.String("1")},
+	}
+	got := sortResourceRequirements(reqs)
+
+	want := []batchTypes.ResourceType{batchTypes.ResourceTypeVcpu, batchTypes.ResourceTypeMemory, batchTypes.ResourceTypeGpu}
+	for i, r := range got {
+		if r.Type != want[i] {
+			t.Errorf("got[%d].Type = %s, want %s", i, r.Type, want[i])
+		}
+	}
+	if reqs[0].Type != batchTypes.ResourceTypeGpu {
+		t.Error("sortResourceRequirements must not modify its input")
+	}
+}
+
+func statusTestApp(t *testing.T) *App {
+	t.Helper()
+	app := verifyApp(t, `{"jobDefinitionName": "my-job", "type": "container"}`)
+	app.batchClients = map[string]batchAPI{"us-east-1": &fakeBatchClient{
+		describeJobDefinitions: func(in *batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+			return &batch.DescribeJobDefinitionsOutput{JobDefinitions: []batchTypes.JobDefinition{
+				{Revision: aws.Int32(1), JobDefinitionName: aws.String("my-job")},
+				{
+					JobDefinitionArn:  aws.String("arn:aws:batch:us-east-1:123456789012:job-definition/my-job:2"),
+					JobDefinitionName: aws.String("my-job"),
+					Revision:          aws.Int32(2),
+					Status:            aws.String("ACTIVE"),
+					Type:              aws.String("container"),
+					ContainerProperties: &batchTypes.ContainerProperties{
+						Image: aws.String("busybox:latest"),
+						ResourceRequirements: []batchTypes.ResourceRequirement{
+							{Type: batchTypes.ResourceTypeMemory, Value: aws.String("2048")},
+							{Type: batchTypes.ResourceTypeVcpu, Value: aws.String("1")},
+						},
+					},
+				},
+			}}, nil
+		},
+	}}
+	return app
+}
+
+func TestStatus_Template(t *testing.T) {
+	app := statusTestApp(t)
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Status(context.Background(), StatusOption{Template: "{{.Revision}} {{.Image}}{{range .ResourceRequirements}} {{.Type}}={{.Value}}{{end}}"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "2 busybox:latest VCPU=1 MEMORY=2048\n"; out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+}
+
+func TestStatus_JSON(t *testing.T) {
+	app := statusTestApp(t)
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Status(context.Background(), StatusOption{Output: "json"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var res StatusResult
+	if err := json.Unmarshal([]byte(out), &res); err != nil {
+		t.Fatalf("invalid JSON %q: %v", out, err)
+	}
+	if res.Revision != 2 || res.Image != "busybox:latest" || res.ActiveRevisions != 2 {
+		t.Errorf("unexpected result: %+v", res)
+	}
+}
+
+func TestStatus_InvalidTemplate(t *testing.T) {
+	app := statusTestApp(t)
+	app.batchClients = nil
+	err := app.Status(context.Background(), StatusOption{Template: "{{.Revision"})
+	if err == nil || !strings.Contains(err.Error(), "invalid --template") {
+		t.Errorf("expected invalid template error, got %v", err)
+	}
+}
+
+func TestStatus_NoRender(t *testing.T) {
+	app := statusTestApp(t)
+	// A template that cannot be rendered must not block --no-render.
+	app.config.JobDefinition = "missing.json"
+
+	if err := app.Status(context.Background(), StatusOption{Output: "json"}); err == nil {
+		t.Fatal("expected a render error without --name")
+	}
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Status(context.Background(), StatusOption{Template: "{{.Name}}:{{.Revision}}", Name: "my-job"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if out != "my-job:2\n" {
+		t.Errorf("output = %q", out)
+	}
+}
+
+func TestStatus_Wide(t *testing.T) {
+	app := statusTestApp(t)
+	fake := app.batchClients["us-east-1"].(*fakeBatchClient)
+	describe := fake.describeJobDefinitions
+	fake.describeJobDefinitions = func(in *batch.DescribeJobDefinitionsInput) (*batch.DescribeJobDefinitionsOutput, error) {
+		out, err := describe(in)
+		cp := out.JobDefinitions[1].ContainerProperties
+		cp.Command = []string{"sh", "-c", "echo hello"}
+		cp.Environment = []batchTypes.KeyValuePair{
+			{Name: aws.String("APP_ENV"), Value: aws.String("production")},
+			{Name: aws.String("LONG"), Value: aws.String(strings.Repeat("x", 100))},
+			{Name: aws.String("EMPTY")},
+		}
+		cp.Secrets = []batchTypes.Secret{{Name: aws.String("DB_PASSWORD"), ValueFrom: aws.String("arn:aws:ssm:us-east-1:123456789012:parameter/db")}}
+		cp.JobRoleArn = aws.String("arn:aws:iam::123456789012:role/job")
+		return out, err
+	}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Status(context.Background(), StatusOption{Output: "wide"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{
+		`Command:  sh -c "echo hello"`,
+		"  APP_ENV=production\n",
+		"  LONG=" + strings.Repeat("x", 79) + "…\n",
+		"  EMPTY=\n",
+		"JobRoleArn:       arn:aws:iam::123456789012:role/job\n",
+		"ExecutionRoleArn: \n",
+		"use --output json for the full values",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "DB_PASSWORD") {
+		t.Errorf("wide output must not include secrets:\n%s", out)
+	}
+}
+
+func TestStatus_WideEmptyContainer(t *testing.T) {
+	app := statusTestApp(t)
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Status(context.Background(), StatusOption{Output: "wide"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out, "Environment: (none)") || strings.Contains(out, "truncated") {
+		t.Errorf("unexpected wide output:\n%s", out)
+	}
+}
+
+func TestStatus_Env(t *testing.T) {
+	app := statusTestApp(t)
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Status(context.Background(), StatusOption{Output: "env"})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `BATCHA_REGION=us-east-1
+BATCHA_NAME=my-job
+BATCHA_REVISION=2
+BATCHA_ARN=arn:aws:batch:us-east-1:123456789012:job-definition/my-job:2
+BATCHA_STATUS=ACTIVE
+BATCHA_IMAGE=busybox:latest
+BATCHA_ACTIVE_REVISIONS=2
+`
+	if out != want {
+		t.Errorf("output = %q, want %q", out, want)
+	}
+
+	app.config.Regions = []RegionConfig{{Region: "us-east-1"}, {Region: "us-west-2"}}
+	if err := app.Status(context.Background(), StatusOption{Output: "env"}); err == nil || !strings.Contains(err.Error(), "supports a single region") {
+		t.Errorf("expected an error with several regions, got: %v", err)
+	}
+}
+
+func TestStatus_MultiRegionMachineOutput(t *testing.T) {
+	app := statusTestApp(t)
+	app.batchClients["us-west-2"] = app.batchClients["us-east-1"]
+	app.config.Regions = []RegionConfig{{Region: "us-east-1"}, {Region: "us-west-2"}}
+
+	var err error
+	out := captureStdout(t, func() {
+		err = app.Status(context.Background(), StatusOption{Output: "json"})
+	})
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	var results []StatusResult
+	if err := json.Unmarshal([]byte(out), &results); err != nil {
+		t.Fatalf("output is not a single JSON array: %v\n%s", err, out)
+	}
+	if len(results) != 2 || results[0].Region != "us-east-1" || results[1].Region != "us-west-2" {
+		t.Errorf("results = %+v, want one per region", results)
+	}
+
+	out = captureStdout(t, func() {
+		err = app.Status(context.Background(), StatusOption{Template: "{{.Region}} {{.Revision}}"})
+	})
+	if err != nil {
+		t.Fatalf("Status failed: %v", err)
+	}
+	if want := "us-east-1 2\nus-west-2 2\n"; out != want {
+		t.Errorf("template output = %q, want %q", out, want)
+	}
+}
+
+func TestShellQuote(t *testing.T) {
+	for in, want := range map[string]string{
+		"busybox:latest": "busybox:latest",
+		"":               "''",
+		"my image":       "'my image'",
+		"$(rm -rf /)":    "'$(rm -rf /)'",
+		"it's":           `'it'\''s'`,
+		"a\nb":           "'a\nb'",
+	} {
+		if got := shellQuote(in); got != want {
+			t.Errorf("shellQuote(%q) = %s, want %s", in, got, want)
+		}
+	}
+}
@@ -3,46 +3,444 @@ package batcha
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"iter"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
+	"unicode/utf8"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/batch"
 	batchTypes "github.com/aws/aws-sdk-go-v2/service/batch/types"
 )
 
+// VerifyOption holds options for the verify command.
+type VerifyOption struct {
+	// DebugGoStruct prints the unmarshaled RegisterJobDefinitionInput.
+	DebugGoStruct bool
+	// Output is the output format: "text" (default) or "json".
+	Output string
+
+	// Pattern selects the config files found by VerifyDir by base name
+	// (default "*.yml").
+	Pattern string
+	// Concurrency is the number of configs VerifyDir checks at once.
+	Concurrency int
+
+	// ReportFile is a file the JSON report is also written to, whatever
+	// Output is and even when verification fails.
+	ReportFile string
+}
+
+// verifyReport is the result printed by verify --output json.
+type verifyReport struct {
+	// Config is the config file path, set by VerifyDir.
+	Config    string   `json:"config,omitempty"`
+	OK        bool     `json:"ok"`
+	SizeBytes int      `json:"sizeBytes,omitempty"`
+	Errors    []string `json:"errors"`
+	Warnings  []string `json:"warnings"`
+}
+
 // Verify validates the job definition template locally without calling AWS.
-func (app *App) Verify(ctx context.Context) error {
+func (app *App) Verify(ctx context.Context, opt VerifyOption) error {
+	switch opt.Output {
+	case "", "text":
+	case "json":
+		return app.verifyJSON(ctx, opt.ReportFile)
+	default:
+		return fmt.Errorf("unknown output format %q (expected text or json)", opt.Output)
+	}
+
 	rendered, err := app.render(ctx)
 	if err != nil {
-		return fmt.Errorf("render: %w", err)
+		err = fmt.Errorf("render: %w", err)
+		return errors.Join(err, writeVerifyReport(opt.ReportFile, failedVerifyReport(err)))
 	}
 	fmt.Println("OK: template rendered successfully")
 
+	res, err := app.checkRendered(rendered)
+	if err != nil {
+		return errors.Join(err, writeVerifyReport(opt.ReportFile, failedVerifyReport(err)))
+	}
+	if err := writeVerifyReport(opt.ReportFile, res.report()); err != nil {
+		return err
+	}
+	if res.input != nil {
+		fmt.Println("OK: valid RegisterJobDefinitionInput structure")
+		fmt.Printf("OK: definition size %d bytes (limit %d)\n", res.size, maxDefinitionBytes)
+		if opt.DebugGoStruct {
+			dumpGoStruct(os.Stdout, res.input)
+		}
+	}
+	for _, w := range res.warns {
+		fmt.Printf("WARN: %s\n", w)
+	}
+
+	if len(res.errs) > 0 {
+		for _, e := range res.errs {
+			fmt.Printf("NG: %s\n", e)
+		}
+		return fmt.Errorf("verification failed with %d error(s)", len(res.errs))
+	}
+
+	fmt.Println("OK: all validations passed")
+	fmt.Println("Verify OK")
+	return nil
+}
+
+// verifyJSON is Verify with the findings printed as a single verifyReport.
+// Failures that stop the checks (e.g. rendering) are reported as the only
+// error. The returned error matches the text output's.
+func (app *App) verifyJSON(ctx context.Context, reportFile string) error {
+	report, err := app.buildVerifyReport(ctx)
+	if perr := printJSON(report); perr != nil {
+		return perr
+	}
+	if werr := writeVerifyReport(reportFile, report); werr != nil {
+		return werr
+	}
+
+	switch {
+	case err != nil:
+		return err
+	case !report.OK:
+		return fmt.Errorf("verification failed with %d error(s)", len(report.Errors))
+	}
+	return nil
+}
+
+// buildVerifyReport renders the template and runs every check. A failure
+// that stops the checks is returned and also recorded as the only error.
+func (app *App) buildVerifyReport(ctx context.Context) (verifyReport, error) {
+	rendered, err := app.render(ctx)
+	if err != nil {
+		err = fmt.Errorf("render: %w", err)
+		return failedVerifyReport(err), err
+	}
+	res, err := app.checkRendered(rendered)
+	if err != nil {
+		return failedVerifyReport(err), err
+	}
+	return res.report(), nil
+}
+
+// failedVerifyReport is the report of a template that could not be checked.
+func failedVerifyReport(err error) verifyReport {
+	return verifyReport{Errors: []string{err.Error()}, Warnings: []string{}}
+}
+
+// writeVerifyReport writes report as indented JSON to path. An empty path
+// writes nothing.
+func writeVerifyReport(path string, report any) error {
+	if path == "" {
+		return nil
+	}
+	b, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal verify report: %w", err)
+	}
+	if err := os.WriteFile(path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("failed to write verify report: %w", err)
+	}
+	return nil
+}
+
+// verifyResult holds the findings of checkRendered.
+type verifyResult struct {
+	// input is nil when the template could not be unmarshaled.
+	input *batch.RegisterJobDefinitionInput
+	// size is the marshaled definition size in bytes.
+	size  int
+	errs  []string
+	warns []string
+}
+
+// report returns the findings as a verifyReport.
+func (res verifyResult) report() verifyReport {
+	return verifyReport{
+		OK:        len(res.errs) == 0,
+		SizeBytes: res.size,
+		Errors:    append([]string{}, res.errs...),
+		Warnings:  append([]string{}, res.warns...),
+	}
+}
+
+// checkRendered runs every verify check on a rendered template. An error is
+// returned only when the template cannot be checked at all.
+func (app *App) checkRendered(rendered map[string]any) (verifyResult, error) {
 	converted := walkMap(rendered, toPascalCase)
 	jsonBytes, err := json.Marshal(converted)
 	if err != nil {
-		return fmt.Errorf("marshal: %w", err)
+		return verifyResult{}, fmt.Errorf("marshal: %w", err)
 	}
 
+	// Checks on the rendered map run first: they see JSON types that are
+	// lost (or make unmarshaling fail) once converted to SDK types.
+	res := verifyResult{errs: validateRendered(rendered)}
+	res.errs = append(res.errs, validateRequiredTags(rendered, app.config.Verify.RequiredTags)...)
+
 	var input batch.RegisterJobDefinitionInput
 	if err := json.Unmarshal(jsonBytes, &input); err != nil {
-		return fmt.Errorf("unmarshal into RegisterJobDefinitionInput: %w", err)
+		if len(res.errs) == 0 {
+			return verifyResult{}, fmt.Errorf("unmarshal into RegisterJobDefinitionInput: %w", err)
+		}
+		return res, nil
 	}
-	fmt.Println("OK: valid RegisterJobDefinitionInput structure")
+	res.input = &input
+	res.size = len(jsonBytes)
+	res.errs = append(res.errs, validateInput(&input)...)
+	res.errs = append(res.errs, validatePolicy(&input, app.config.Verify)...)
+	res.warns = warnInput(&input, app.config)
+	sizeErrs, sizeWarns := checkDefinitionSize(res.size, &input)
+	res.errs = append(res.errs, sizeErrs...)
+	res.warns = append(res.warns, sizeWarns...)
+	return res, nil
+}
 
-	errs := validateInput(&input)
+const (
+	// maxDefinitionBytes is the largest job definition AWS Batch accepts.
+	maxDefinitionBytes = 24 * 1024
+	// maxEnvironmentEntries is the per-container count of environment or
+	// secrets entries above which verify warns.
+	maxEnvironmentEntries = 100
+)
 
-	if len(errs) > 0 {
-		for _, e := range errs {
-			fmt.Printf("NG: %s\n", e)
+// checkDefinitionSize fails definitions over maxDefinitionBytes and warns
+// from 80% of it, and on containers with many environment or secrets
+// entries (often inlined values that belong in a file or SSM).
+func checkDefinitionSize(size int, input *batch.RegisterJobDefinitionInput) (errs, warns []string) {
+	switch {
+	case size > maxDefinitionBytes:
+		errs = append(errs, fmt.Sprintf("job definition is %d bytes, over the AWS limit of %d bytes", size, maxDefinitionBytes))
+	case size >= maxDefinitionBytes*8/10:
+		warns = append(warns, fmt.Sprintf("job definition is %d bytes, %d%% of the AWS limit of %d bytes", size, size*100/maxDefinitionBytes, maxDefinitionBytes))
+	}
+	for _, c := range containers(input) {
+		if n := len(c.props.Environment); n > maxEnvironmentEntries {
+			warns = append(warns, fmt.Sprintf("%s.environment has %d entries (more than %d)", c.path, n, maxEnvironmentEntries))
+		}
+		if n := len(c.props.Secrets); n > maxEnvironmentEntries {
+			warns = append(warns, fmt.Sprintf("%s.secrets has %d entries (more than %d)", c.path, n, maxEnvironmentEntries))
+		}
+	}
+	return errs, warns
+}
+
+// validateRendered checks the rendered template before it is converted into
+// SDK types.
+func validateRendered(rendered map[string]any) []string {
+	var errs []string
+
+	if v, ok := lookupKey(rendered, "propagateTags"); ok {
+		if _, isBool := v.(bool); !isBool {
+			errs = append(errs, fmt.Sprintf("propagateTags must be a boolean, got %v", v))
+		}
+	}
+	if v, ok := lookupKey(rendered, "tags"); ok {
+		errs = append(errs, validateTags(v)...)
+	}
+	for _, key := range initExcludeKeys {
+		if _, ok := lookupKey(rendered, key); ok {
+			errs = append(errs, fmt.Sprintf("%s is managed by AWS and must not be set in the template; remove it", toCamelCase(key)))
+		}
+	}
+	for path, container := range renderedContainers(rendered) {
+		if env, ok := lookupKey(container, "environment"); ok {
+			errs = append(errs, validateEnvironment(path+".environment", env)...)
+		}
+		if mounts, ok := lookupKey(container, "mountPoints"); ok {
+			errs = append(errs, validateMountPoints(path+".mountPoints", mounts)...)
+		}
+		if reqs, ok := lookupKey(container, "resourceRequirements"); ok {
+			errs = append(errs, validateResourceRequirementValues(path+".resourceRequirements", reqs)...)
 		}
-		return fmt.Errorf("verification failed with %d error(s)", len(errs))
 	}
 
-	fmt.Println("OK: all validations passed")
-	fmt.Println("Verify OK")
-	return nil
+	return errs
+}
+
+// renderedContainers returns the container objects of the rendered template
+// with their paths: containerProperties first, then each node range.
+func renderedContainers(rendered map[string]any) iter.Seq2[string, map[string]any] {
+	return func(yield func(string, map[string]any) bool) {
+		if v, ok := lookupKey(rendered, "containerProperties"); ok {
+			if cp, ok := v.(map[string]any); ok && !yield("containerProperties", cp) {
+				return
+			}
+		}
+		np, _ := lookupKey(rendered, "nodeProperties")
+		npMap, _ := np.(map[string]any)
+		ranges, _ := lookupKey(npMap, "nodeRangeProperties")
+		rangeList, _ := ranges.([]any)
+		for i, nr := range rangeList {
+			nrMap, _ := nr.(map[string]any)
+			c, _ := lookupKey(nrMap, "container")
+			if cp, ok := c.(map[string]any); ok {
+				if !yield(fmt.Sprintf("nodeProperties.nodeRangeProperties[%d].container", i), cp) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// validateEnvironment checks that every environment value is a string.
+// JSON numbers and booleans are rejected by AWS.
+func validateEnvironment(path string, v any) []string {
+	entries, ok := v.([]any)
+	if !ok {
+		return []string{fmt.Sprintf("%s must be an array", path)}
+	}
+	var errs []string
+	for i, e := range entries {
+		entry, ok := e.(map[string]any)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("%s[%d] must be an object with name and value", path, i))
+			continue
+		}
+		value, ok := lookupKey(entry, "value")
+		if !ok {
+			continue
+		}
+		if _, isString := value.(string); !isString {
+			name, _ := lookupKey(entry, "name")
+			errs = append(errs, fmt.Sprintf("%s[%d] (%v) value must be a string, got %v", path, i, name, value))
+		}
+	}
+	return errs
+}
+
+// validateResourceRequirementValues checks that every resource requirement
+// value is a string such as "2048", not a JSON number.
+func validateResourceRequirementValues(path string, v any) []string {
+	reqs, ok := v.([]any)
+	if !ok {
+		return []string{fmt.Sprintf("%s must be an array", path)}
+	}
+	var errs []string
+	for i, r := range reqs {
+		req, ok := r.(map[string]any)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("%s[%d] must be an object with type and value", path, i))
+			continue
+		}
+		value, ok := lookupKey(req, "value")
+		if !ok {
+			continue
+		}
+		if _, isString := value.(string); !isString {
+			typ, _ := lookupKey(req, "type")
+			errs = append(errs, fmt.Sprintf("%s[%d] (%v) value must be a string, got %v; quote it", path, i, typ, value))
+		}
+	}
+	return errs
+}
+
+// validateMountPoints checks that every containerPath is absolute and every
+// readOnly is a boolean.
+func validateMountPoints(path string, v any) []string {
+	mounts, ok := v.([]any)
+	if !ok {
+		return []string{fmt.Sprintf("%s must be an array", path)}
+	}
+	var errs []string
+	for i, m := range mounts {
+		mount, ok := m.(map[string]any)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("%s[%d] must be an object", path, i))
+			continue
+		}
+		if cp, ok := lookupKey(mount, "containerPath"); ok {
+			if s, isString := cp.(string); !isString || !strings.HasPrefix(s, "/") {
+				errs = append(errs, fmt.Sprintf("%s[%d].containerPath must be an absolute path, got %v", path, i, cp))
+			}
+		}
+		if ro, ok := lookupKey(mount, "readOnly"); ok {
+			if _, isBool := ro.(bool); !isBool {
+				errs = append(errs, fmt.Sprintf("%s[%d].readOnly must be a boolean, got %v", path, i, ro))
+			}
+		}
+	}
+	return errs
+}
+
+// validateRequiredTags checks that every required tag is present with a
+// non-empty value. Tag keys keep their case through walkMap, so they are
+// read from the rendered map.
+func validateRequiredTags(rendered map[string]any, required []string) []string {
+	if len(required) == 0 {
+		return nil
+	}
+	v, _ := lookupKey(rendered, "tags")
+	tags, _ := v.(map[string]any)
+
+	var errs []string
+	for _, key := range required {
+		value, ok := tags[key]
+		switch {
+		case !ok:
+			errs = append(errs, fmt.Sprintf("required tag %q is missing", key))
+		case value == nil || value == "":
+			errs = append(errs, fmt.Sprintf("required tag %q has an empty value", key))
+		}
+	}
+	return errs
+}
+
+// AWS tag limits per resource.
+const (
+	maxTags           = 50
+	maxTagKeyLength   = 128
+	maxTagValueLength = 256
+)
+
+func validateTags(v any) []string {
+	tags, ok := v.(map[string]any)
+	if !ok {
+		return []string{"tags must be an object of key/value strings"}
+	}
+
+	var errs []string
+	if len(tags) > maxTags {
+		errs = append(errs, fmt.Sprintf("tags has %d entries (maximum %d)", len(tags), maxTags))
+	}
+	keys := make([]string, 0, len(tags))
+	for k := range tags {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if n := utf8.RuneCountInString(k); n > maxTagKeyLength {
+			errs = append(errs, fmt.Sprintf("tag key %q is %d characters (maximum %d)", k, n, maxTagKeyLength))
+		}
+		value, ok := tags[k].(string)
+		if !ok {
+			errs = append(errs, fmt.Sprintf("tags.%s must be a string, got %v", k, tags[k]))
+			continue
+		}
+		if n := utf8.RuneCountInString(value); n > maxTagValueLength {
+			errs = append(errs, fmt.Sprintf("tags.%s value is %d characters (maximum %d)", k, n, maxTagValueLength))
+		}
+	}
+	return errs
+}
+
+// lookupKey returns m[key], matching keys regardless of the case of their
+// first letter (templates may use camelCase or PascalCase).
+func lookupKey(m map[string]any, key string) (any, bool) {
+	want := toPascalCase(key)
+	for k, v := range m {
+		if toPascalCase(k) == want {
+			return v, true
+		}
+	}
+	return nil, false
 }
 
 func validateInput(input *batch.RegisterJobDefinitionInput) []string {
@@ -50,31 +448,464 @@ func validateInput(input *batch.RegisterJobDefinitionInput) []string {
 
 	if input.JobDefinitionName == nil || *input.JobDefinitionName == "" {
 		errs = append(errs, "jobDefinitionName is required")
+	} else {
+		errs = append(errs, validateJobDefinitionName(*input.JobDefinitionName)...)
 	}
 
 	if string(input.Type) == "" {
 		errs = append(errs, "type is required")
-	}
-
-	isFargate := false
-	for _, pc := range input.PlatformCapabilities {
-		if pc == batchTypes.PlatformCapabilityFargate {
-			isFargate = true
-		}
+	} else {
+		errs = append(errs, validateJobDefinitionType(input.Type)...)
 	}
 
 	switch string(input.Type) {
 	case "container":
-		errs = append(errs, validateContainerProperties(input.ContainerProperties, isFargate)...)
+		errs = append(errs, validateContainerProperties(input.ContainerProperties, isFargate(input))...)
 	case "multinode":
 		if input.NodeProperties == nil {
 			errs = append(errs, "nodeProperties is required when type is \"multinode\"")
+		} else {
+			errs = append(errs, validateNodeRanges(input.NodeProperties, isFargate(input))...)
+		}
+	}
+
+	for _, c := range containers(input) {
+		errs = append(errs, validateRepositoryCredentials(c.path+".repositoryCredentials", c.props.RepositoryCredentials)...)
+	}
+
+	if input.Timeout != nil && input.Timeout.AttemptDurationSeconds != nil {
+		if d := aws.ToInt32(input.Timeout.AttemptDurationSeconds); d < minAttemptDurationSeconds {
+			errs = append(errs, fmt.Sprintf("timeout.attemptDurationSeconds %d is below the minimum of %d seconds", d, minAttemptDurationSeconds))
 		}
 	}
 
 	return errs
 }
 
+// validateJobDefinitionType checks that typ is a type AWS Batch accepts.
+// Values are case-sensitive.
+func validateJobDefinitionType(typ batchTypes.JobDefinitionType) []string {
+	allowed := typ.Values()
+	if slices.Contains(allowed, typ) {
+		return nil
+	}
+	for _, v := range allowed {
+		if strings.EqualFold(string(v), string(typ)) {
+			return []string{fmt.Sprintf("type %q is not valid (did you mean %q?)", typ, v)}
+		}
+	}
+	names := make([]string, len(allowed))
+	for i, v := range allowed {
+		names[i] = string(v)
+	}
+	return []string{fmt.Sprintf("type %q is not valid (allowed: %s)", typ, strings.Join(names, ", "))}
+}
+
+// isFargate reports whether platformCapabilities includes FARGATE.
+func isFargate(input *batch.RegisterJobDefinitionInput) bool {
+	return slices.Contains(input.PlatformCapabilities, batchTypes.PlatformCapabilityFargate)
+}
+
+// maxJobDefinitionNameLength is the longest job definition name AWS Batch accepts.
+const maxJobDefinitionNameLength = 128
+
+var jobDefinitionNamePattern = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+
+func validateJobDefinitionName(name string) []string {
+	var errs []string
+	if len(name) > maxJobDefinitionNameLength {
+		errs = append(errs, fmt.Sprintf("jobDefinitionName %q is %d characters, exceeds %d", name, len(name), maxJobDefinitionNameLength))
+	}
+	if !jobDefinitionNamePattern.MatchString(name) {
+		errs = append(errs, fmt.Sprintf("jobDefinitionName %q may only contain letters, numbers, hyphens and underscores", name))
+	}
+	return errs
+}
+
+// minAttemptDurationSeconds is the smallest job timeout AWS Batch accepts.
+const minAttemptDurationSeconds = 60
+
+// warnInput returns findings that do not block registration but are likely
+// mistakes.
+func warnInput(input *batch.RegisterJobDefinitionInput, cfg *Config) []string {
+	var warns []string
+
+	for _, c := range containers(input) {
+		warns = append(warns, warnImageRegion(c.path+".image", aws.ToString(c.props.Image), cfg.Region)...)
+		warns = append(warns, warnLogRegion(c.path+".logConfiguration", c.props.LogConfiguration, cfg.Region)...)
+		warns = append(warns, warnEnvironmentSecrets(c.path+".environment", c.props.Environment)...)
+		if c.props.RepositoryCredentials == nil {
+			warns = append(warns, warnPrivateRegistry(c.path+".image", aws.ToString(c.props.Image))...)
+		}
+	}
+	if cp := input.ContainerProperties; cp != nil {
+		// An explicit [] overrides the image CMD with nothing, unlike omitting command.
+		if cp.Command != nil && len(cp.Command) == 0 {
+			warns = append(warns, "containerProperties.command is an empty array (it overrides the image CMD with nothing; omit it to use the image default)")
+		}
+	}
+
+	if string(input.Type) == "multinode" && input.NodeProperties != nil {
+		warns = append(warns, warnMultinode(input)...)
+	}
+	if isFargate(input) {
+		warns = append(warns, warnFargateNetwork(input)...)
+	} else {
+		warns = append(warns, warnFargateOnlyFields(input)...)
+	}
+	warns = append(warns, warnPropagateTags(input)...)
+	if input.SchedulingPriority != nil {
+		warns = append(warns, fmt.Sprintf("schedulingPriority %d only applies on job queues with a fair-share scheduling policy (it is ignored on FIFO queues)", aws.ToInt32(input.SchedulingPriority)))
+	}
+	warns = append(warns, warnPolicy(input, cfg.Verify)...)
+	warns = append(warns, warnRetryTimeout(input, cfg.Verify.MinAttemptDurationSeconds)...)
+
+	return warns
+}
+
+var (
+	// secretNamePattern matches environment variable names that usually
+	// hold credentials.
+	secretNamePattern   = regexp.MustCompile(`(?i)PASSWORD|PASSWD|SECRET|TOKEN|PRIVATE_KEY|API_KEY`)
+	awsAccessKeyPattern = regexp.MustCompile(`\b(AKIA|ASIA)[0-9A-Z]{16}\b`)
+	// Long base64 strings need mixed case and a digit, so lowercase hex
+	// such as git commit SHAs is not reported.
+	base64Pattern = regexp.MustCompile(`^[A-Za-z0-9+/]{40,}={0,2}$`)
+	hexPattern    = regexp.MustCompile(`^[0-9a-fA-F]{64,}$`)
+)
+
+// warnEnvironmentSecrets reports environment values that look like
+// credentials, which belong in secrets (valueFrom) instead. Values are never
+// included in the messages.
+func warnEnvironmentSecrets(path string, env []batchTypes.KeyValuePair) []string {
+	var warns []string
+	for i, kv := range env {
+		name, value := aws.ToString(kv.Name), aws.ToString(kv.Value)
+		if value == "" || strings.HasPrefix(value, "arn:") {
+			continue
+		}
+		var reason string
+		switch {
+		case awsAccessKeyPattern.MatchString(value):
+			reason = "looks like an AWS access key ID"
+		case secretNamePattern.MatchString(name):
+			reason = "is named like a credential"
+		case hexPattern.MatchString(value):
+			reason = "looks like a hex-encoded key"
+		case base64Pattern.MatchString(value) && isMixedBase64(value):
+			reason = "looks like a base64-encoded key"
+		default:
+			continue
+		}
+		warns = append(warns, fmt.Sprintf("%s[%d] %q %s; move it to secrets (valueFrom Secrets Manager or SSM) instead of plaintext environment", path, i, name, reason))
+	}
+	return warns
+}
+
+// isMixedBase64 reports whether s has upper and lower case letters and a digit.
+func isMixedBase64(s string) bool {
+	return strings.ContainsAny(s, "ABCDEFGHIJKLMNOPQRSTUVWXYZ") &&
+		strings.ContainsAny(s, "abcdefghijklmnopqrstuvwxyz") &&
+		strings.ContainsAny(s, "0123456789")
+}
+
+// warnFargateNetwork checks networkConfiguration on Fargate containers.
+// Subnets and security groups come from the compute environment, so only
+// assignPublicIp can be checked here.
+func warnFargateNetwork(input *batch.RegisterJobDefinitionInput) []string {
+	var warns []string
+	for _, c := range containers(input) {
+		nc := c.props.NetworkConfiguration
+		if nc == nil {
+			continue
+		}
+		switch nc.AssignPublicIp {
+		case batchTypes.AssignPublicIpEnabled, batchTypes.AssignPublicIpDisabled:
+		case "":
+			warns = append(warns, fmt.Sprintf("%s.networkConfiguration is set without assignPublicIp (defaults to DISABLED; tasks in a public subnet without a NAT gateway cannot pull images)", c.path))
+		default:
+			warns = append(warns, fmt.Sprintf("%s.networkConfiguration.assignPublicIp %q is not ENABLED or DISABLED", c.path, nc.AssignPublicIp))
+		}
+	}
+	return warns
+}
+
+// propagateTagsHintThreshold is the tag count from which a definition without
+// propagateTags is reported; a few tags are often only meant for the job
+// definition itself.
+const propagateTagsHintThreshold = 3
+
+// warnPropagateTags reports propagateTags without any tags to propagate, and
+// several tags that stay on the job definition because propagateTags is off.
+func warnPropagateTags(input *batch.RegisterJobDefinitionInput) []string {
+	propagate := aws.ToBool(input.PropagateTags)
+	switch {
+	case propagate && len(input.Tags) == 0:
+		return []string{"propagateTags is true but tags is empty (nothing is propagated)"}
+	case !propagate && len(input.Tags) >= propagateTagsHintThreshold:
+		return []string{fmt.Sprintf("%d tags are set but propagateTags is not true (they are not propagated to the ECS tasks)", len(input.Tags))}
+	}
+	return nil
+}
+
+// warnFargateOnlyFields reports Fargate-only container fields on a definition
+// that does not run on Fargate. AWS Batch ignores them on EC2; they are
+// usually left over from converting a Fargate definition.
+func warnFargateOnlyFields(input *batch.RegisterJobDefinitionInput) []string {
+	var warns []string
+	for _, c := range containers(input) {
+		if c.props.FargatePlatformConfiguration != nil {
+			warns = append(warns, fmt.Sprintf("%s.fargatePlatformConfiguration is ignored on EC2 (platformCapabilities does not include FARGATE)", c.path))
+		}
+		if nc := c.props.NetworkConfiguration; nc != nil && nc.AssignPublicIp != "" {
+			warns = append(warns, fmt.Sprintf("%s.networkConfiguration.assignPublicIp is ignored on EC2 (platformCapabilities does not include FARGATE)", c.path))
+		}
+	}
+	return warns
+}
+
+// warnMultinode checks the job-level timeout and the node ranges of a
+// multinode definition.
+func warnMultinode(input *batch.RegisterJobDefinitionInput) []string {
+	var warns []string
+	np := input.NodeProperties
+
+	// For multinode jobs the timeout applies to the whole job; without one a
+	// hung node holds every instance until someone notices.
+	if input.Timeout == nil || input.Timeout.AttemptDurationSeconds == nil {
+		warns = append(warns, "timeout.attemptDurationSeconds is not set for multinode job (the job can hold all nodes indefinitely)")
+	}
+
+	numNodes := aws.ToInt32(np.NumNodes)
+	if numNodes <= 0 {
+		warns = append(warns, "nodeProperties.numNodes must be at least 1")
+		return warns
+	}
+	if mainNode := aws.ToInt32(np.MainNode); mainNode < 0 || mainNode >= numNodes {
+		warns = append(warns, fmt.Sprintf("nodeProperties.mainNode %d is outside of 0-%d", mainNode, numNodes-1))
+	}
+
+	covered := make([]int, numNodes) // index of the range covering each node, +1
+	for i, nr := range np.NodeRangeProperties {
+		start, end, err := parseTargetNodes(aws.ToString(nr.TargetNodes), numNodes)
+		if err != nil {
+			warns = append(warns, fmt.Sprintf("nodeProperties.nodeRangeProperties[%d].targetNodes: %s", i, err))
+			continue
+		}
+		for n := start; n <= end; n++ {
+			if prev := covered[n]; prev != 0 {
+				warns = append(warns, fmt.Sprintf("nodeProperties.nodeRangeProperties[%d].targetNodes overlaps nodeRangeProperties[%d] at node %d", i, prev-1, n))
+				break
+			}
+			covered[n] = i + 1
+		}
+	}
+	for n, c := range covered {
+		if c == 0 {
+			warns = append(warns, fmt.Sprintf("node %d is not covered by any nodeProperties.nodeRangeProperties targetNodes", n))
+			break
+		}
+	}
+
+	return warns
+}
+
+// validatePolicy enforces the organization policies configured under
+// verify in the config file.
+func validatePolicy(input *batch.RegisterJobDefinitionInput, policy VerifyConfig) []string {
+	var errs []string
+
+	if len(policy.AllowedImagePrefixes) > 0 {
+		for _, c := range containers(input) {
+			image := aws.ToString(c.props.Image)
+			if image == "" {
+				continue // reported as missing elsewhere
+			}
+			allowed := false
+			for _, prefix := range policy.AllowedImagePrefixes {
+				if strings.HasPrefix(image, prefix) {
+					allowed = true
+					break
+				}
+			}
+			if !allowed {
+				errs = append(errs, fmt.Sprintf("%s.image %q is not from an allowed registry (allowed prefixes: %s)",
+					c.path, image, strings.Join(policy.AllowedImagePrefixes, ", ")))
+			}
+		}
+	}
+
+	if policy.ForbidPrivileged {
+		for _, c := range containers(input) {
+			if aws.ToBool(c.props.Privileged) {
+				errs = append(errs, fmt.Sprintf("%s.privileged is true, but verify.forbid_privileged is set", c.path))
+			}
+		}
+	}
+
+	return errs
+}
+
+// warnPolicy reports findings of the verify policies that do not fail the
+// definition: containers running as root when forbid_privileged is set.
+func warnPolicy(input *batch.RegisterJobDefinitionInput, policy VerifyConfig) []string {
+	if !policy.ForbidPrivileged {
+		return nil
+	}
+	var warns []string
+	for _, c := range containers(input) {
+		user := aws.ToString(c.props.User)
+		// user may be "uid", "uid:gid", "name" or "name:group".
+		name, _, _ := strings.Cut(user, ":")
+		switch name {
+		case "":
+			warns = append(warns, fmt.Sprintf("%s.user is not set (the container runs as the image's user, often root)", c.path))
+		case "root", "0":
+			warns = append(warns, fmt.Sprintf("%s.user %q runs the container as root", c.path, user))
+		}
+	}
+	return warns
+}
+
+// defaultMinAttemptDurationSeconds is the attempt timeout below which verify
+// warns on retrying definitions unless verify.min_attempt_duration_seconds
+// is set.
+const defaultMinAttemptDurationSeconds = 300
+
+// warnRetryTimeout warns when a definition retries but each attempt times out
+// within floor seconds: attempts that need longer are killed and retried
+// until retryStrategy.attempts runs out, so the job may never complete.
+func warnRetryTimeout(input *batch.RegisterJobDefinitionInput, floor int32) []string {
+	if floor <= 0 {
+		floor = defaultMinAttemptDurationSeconds
+	}
+	if input.RetryStrategy == nil || input.Timeout == nil || input.Timeout.AttemptDurationSeconds == nil {
+		return nil
+	}
+	attempts := aws.ToInt32(input.RetryStrategy.Attempts)
+	duration := aws.ToInt32(input.Timeout.AttemptDurationSeconds)
+	if attempts <= 1 || duration >= floor {
+		return nil
+	}
+	return []string{fmt.Sprintf("retryStrategy.attempts is %d but timeout.attemptDurationSeconds is only %d (below %d); attempts that need longer time out and are retried until the job fails", attempts, duration, floor)}
+}
+
+// containerRef is a container definition and its path in the template.
+type containerRef struct {
+	path  string
+	props *batchTypes.ContainerProperties
+}
+
+// containers returns the container definitions of the job definition:
+// containerProperties and the container of each multinode node range.
+func containers(input *batch.RegisterJobDefinitionInput) []containerRef {
+	var refs []containerRef
+	if input.ContainerProperties != nil {
+		refs = append(refs, containerRef{"containerProperties", input.ContainerProperties})
+	}
+	if np := input.NodeProperties; np != nil {
+		for i, nr := range np.NodeRangeProperties {
+			if nr.Container != nil {
+				refs = append(refs, containerRef{fmt.Sprintf("nodeProperties.nodeRangeProperties[%d].container", i), nr.Container})
+			}
+		}
+	}
+	return refs
+}
+
+// warnLogRegion checks the awslogs-region option of the awslogs log driver.
+// Logs sent to another region are easy to lose track of.
+func warnLogRegion(field string, lc *batchTypes.LogConfiguration, region string) []string {
+	if lc == nil || lc.LogDriver != batchTypes.LogDriverAwslogs {
+		return nil
+	}
+	logRegion, ok := lc.Options["awslogs-region"]
+	if !ok {
+		return []string{fmt.Sprintf("%s.options has no awslogs-region (logs go to the region of the compute environment)", field)}
+	}
+	if region != "" && logRegion != region {
+		return []string{fmt.Sprintf("%s.options.awslogs-region is %s but the job runs in %s", field, logRegion, region)}
+	}
+	return nil
+}
+
+// ecrImagePattern matches ECR image references and captures the registry region,
+// e.g. 123456789012.dkr.ecr.us-east-1.amazonaws.com/app:latest.
+var ecrImagePattern = regexp.MustCompile(`^\d{12}\.dkr\.ecr(?:-fips)?\.([a-z0-9-]+)\.amazonaws\.com(?:\.cn)?/`)
+
+// warnImageRegion warns when an ECR image lives in a different region than
+// the Batch region. Cross-region pulls work but are slow and often unintended.
+func warnImageRegion(field, image, region string) []string {
+	m := ecrImagePattern.FindStringSubmatch(image)
+	if m == nil || region == "" || m[1] == region {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s is in ECR region %s but the job runs in %s", field, m[1], region)}
+}
+
+// secretsManagerARNPattern matches a Secrets Manager secret ARN, e.g.
+// arn:aws:secretsmanager:us-east-1:123456789012:secret:registry-abc123.
+var secretsManagerARNPattern = regexp.MustCompile(`^arn:aws[a-z-]*:secretsmanager:[a-z0-9-]+:\d{12}:secret:.+$`)
+
+// validateRepositoryCredentials checks that credentialsParameter is the ARN
+// of a Secrets Manager secret, the only source AWS Batch accepts for
+// private registry credentials.
+func validateRepositoryCredentials(field string, rc *batchTypes.RepositoryCredentials) []string {
+	if rc == nil {
+		return nil
+	}
+	param := aws.ToString(rc.CredentialsParameter)
+	switch {
+	case param == "":
+		return []string{field + ".credentialsParameter is required"}
+	case !secretsManagerARNPattern.MatchString(param):
+		return []string{fmt.Sprintf("%s.credentialsParameter %q is not a Secrets Manager secret ARN (arn:aws:secretsmanager:<region>:<account>:secret:<name>)", field, param)}
+	}
+	return nil
+}
+
+// warnPrivateRegistry warns when an image is pulled from a registry other
+// than ECR, ECR Public or Docker Hub without repositoryCredentials, which
+// such registries usually require.
+func warnPrivateRegistry(field, image string) []string {
+	host, _, ok := strings.Cut(image, "/")
+	// Without a "." or ":" the first segment is a Docker Hub namespace.
+	if !ok || (!strings.ContainsAny(host, ".:") && host != "localhost") {
+		return nil
+	}
+	if ecrImagePattern.MatchString(image) || host == "public.ecr.aws" || host == "docker.io" || host == "registry-1.docker.io" {
+		return nil
+	}
+	return []string{fmt.Sprintf("%s is pulled from %s without repositoryCredentials (required if the registry is private)", field, host)}
+}
+
+// parseTargetNodes parses a node range such as "0:3", "2:", ":1" or "4" into
+// inclusive start and end indexes within numNodes.
+func parseTargetNodes(s string, numNodes int32) (start, end int, err error) {
+	if s == "" {
+		return 0, 0, fmt.Errorf("must not be empty")
+	}
+	from, to, isRange := strings.Cut(s, ":")
+	if !isRange {
+		to = from
+	}
+	start, end = 0, int(numNodes)-1
+	if from != "" {
+		if start, err = strconv.Atoi(from); err != nil {
+			return 0, 0, fmt.Errorf("%q is not a valid node range", s)
+		}
+	}
+	if to != "" {
+		if end, err = strconv.Atoi(to); err != nil {
+			return 0, 0, fmt.Errorf("%q is not a valid node range", s)
+		}
+	}
+	if start < 0 || end >= int(numNodes) || start > end {
+		return 0, 0, fmt.Errorf("%q is outside of 0-%d", s, numNodes-1)
+	}
+	return start, end, nil
+}
+
 func validateContainerProperties(cp *batchTypes.ContainerProperties, isFargate bool) []string {
 	var errs []string
 
@@ -90,39 +921,82 @@ func validateContainerProperties(cp *batchTypes.ContainerProperties, isFargate b
 		errs = append(errs, "containerProperties.executionRoleArn is required for Fargate")
 	}
 
-	vcpu, memory := "", ""
-	for _, r := range cp.ResourceRequirements {
+	errs = append(errs, validateResourceRequirements("containerProperties", cp.ResourceRequirements, isFargate)...)
+
+	if isFargate {
+		errs = append(errs, validateFargateEphemeralStorage(cp)...)
+		errs = append(errs, validateFargateVolumes(cp)...)
+	}
+
+	for i, arg := range cp.Command {
+		if arg == "" {
+			errs = append(errs, fmt.Sprintf("containerProperties.command[%d] must not be empty", i))
+		}
+	}
+
+	// Validate environment entries have non-empty names
+	for i, env := range cp.Environment {
+		if env.Name == nil || *env.Name == "" {
+			errs = append(errs, fmt.Sprintf("containerProperties.environment[%d].name must not be empty", i))
+		}
+	}
+
+	return errs
+}
+
+// validateResourceRequirements checks the resourceRequirements of the
+// container at path: VCPU and MEMORY are required, and on Fargate they must
+// be a supported combination and GPU is not allowed.
+func validateResourceRequirements(path string, reqs []batchTypes.ResourceRequirement, isFargate bool) []string {
+	var errs []string
+	field := path + ".resourceRequirements"
+
+	vcpu, memory, hasGPU := "", "", false
+	for _, r := range reqs {
 		switch string(r.Type) {
 		case "VCPU":
 			vcpu = aws.ToString(r.Value)
 		case "MEMORY":
 			memory = aws.ToString(r.Value)
+		case "GPU":
+			hasGPU = true
 		}
 	}
 
+	if isFargate && hasGPU {
+		errs = append(errs, field+" includes GPU but platformCapabilities is FARGATE; GPU jobs must run on EC2")
+	}
+
 	if vcpu == "" {
-		errs = append(errs, "containerProperties.resourceRequirements must include VCPU")
+		errs = append(errs, field+" must include VCPU")
 	} else if _, err := strconv.ParseFloat(vcpu, 64); err != nil {
-		errs = append(errs, fmt.Sprintf("VCPU value %q is not a valid number", vcpu))
+		errs = append(errs, fmt.Sprintf("%s: VCPU value %q is not a valid number", field, vcpu))
 	}
 
 	if memory == "" {
-		errs = append(errs, "containerProperties.resourceRequirements must include MEMORY")
+		errs = append(errs, field+" must include MEMORY")
 	} else if _, err := strconv.Atoi(memory); err != nil {
-		errs = append(errs, fmt.Sprintf("MEMORY value %q is not a valid integer", memory))
+		errs = append(errs, fmt.Sprintf("%s: MEMORY value %q is not a valid integer", field, memory))
 	}
 
 	if isFargate && vcpu != "" && memory != "" {
-		errs = append(errs, validateFargateResources(vcpu, memory)...)
+		for _, e := range validateFargateResources(vcpu, memory) {
+			errs = append(errs, field+": "+e)
+		}
 	}
+	return errs
+}
 
-	// Validate environment entries have non-empty names
-	for i, env := range cp.Environment {
-		if env.Name == nil || *env.Name == "" {
-			errs = append(errs, fmt.Sprintf("containerProperties.environment[%d].name must not be empty", i))
+// validateNodeRanges checks the container of every node range.
+func validateNodeRanges(np *batchTypes.NodeProperties, isFargate bool) []string {
+	var errs []string
+	for i, nr := range np.NodeRangeProperties {
+		if nr.Container == nil {
+			continue
 		}
+		path := fmt.Sprintf("nodeProperties.nodeRangeProperties[%d].container", i)
+		errs = append(errs, validateResourceRequirements(path, nr.Container.ResourceRequirements, isFargate)...)
 	}
-
 	return errs
 }
 
@@ -138,6 +1012,59 @@ var fargateMemoryRanges = map[string][3]int{
 	"16":   {32768, 122880, 8192},
 }
 
+// Ephemeral storage above defaultFargateEphemeralStorageGiB requires Fargate
+// platform version 1.4.0 or later.
+const defaultFargateEphemeralStorageGiB = 20
+
+func validateFargateEphemeralStorage(cp *batchTypes.ContainerProperties) []string {
+	if cp.EphemeralStorage == nil || aws.ToInt32(cp.EphemeralStorage.SizeInGiB) <= defaultFargateEphemeralStorageGiB {
+		return nil
+	}
+	if cp.FargatePlatformConfiguration == nil {
+		return nil // LATEST
+	}
+	version := aws.ToString(cp.FargatePlatformConfiguration.PlatformVersion)
+	if version == "" || version == "LATEST" || !versionLess(version, "1.4.0") {
+		return nil
+	}
+	return []string{fmt.Sprintf("containerProperties.ephemeralStorage.sizeInGiB %d requires Fargate platform version 1.4.0 or later (got %s)",
+		aws.ToInt32(cp.EphemeralStorage.SizeInGiB), version)}
+}
+
+// validateFargateVolumes rejects host volumes, which Fargate does not
+// support. EFS volumes and volumes without host (backed by the task's
+// ephemeral storage) are allowed.
+func validateFargateVolumes(cp *batchTypes.ContainerProperties) []string {
+	var errs []string
+	for i, v := range cp.Volumes {
+		if v.Host == nil {
+			continue
+		}
+		errs = append(errs, fmt.Sprintf("containerProperties.volumes[%d] %q uses host, which Fargate does not support (use efsVolumeConfiguration or omit host for ephemeral storage)",
+			i, aws.ToString(v.Name)))
+	}
+	return errs
+}
+
+// versionLess reports whether dotted version a is lower than b.
+// Non-numeric components compare as 0.
+func versionLess(a, b string) bool {
+	pa, pb := strings.Split(a, "."), strings.Split(b, ".")
+	for i := range max(len(pa), len(pb)) {
+		var x, y int
+		if i < len(pa) {
+			x, _ = strconv.Atoi(pa[i])
+		}
+		if i < len(pb) {
+			y, _ = strconv.Atoi(pb[i])
+		}
+		if x != y {
+			return x < y
+		}
+	}
+	return false
+}
+
 func validateFargateResources(vcpu, memory string) []string {
 	r, ok := fargateMemoryRanges[vcpu]
 	if !ok {
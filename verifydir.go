@@ -0,0 +1,137 @@
+package batcha
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+const (
+	// defaultVerifyPattern selects the config files checked by verify --dir.
+	defaultVerifyPattern = "*.yml"
+	// defaultVerifyConcurrency is the number of configs verify --dir checks
+	// at once unless --concurrency is set.
+	defaultVerifyConcurrency = 4
+)
+
+// findConfigs returns the files under dir whose base name matches pattern,
+// in lexical order. Hidden directories such as .git are skipped.
+func findConfigs(dir, pattern string) ([]string, error) {
+	if _, err := filepath.Match(pattern, ""); err != nil {
+		return nil, fmt.Errorf("invalid --pattern %q: %w", pattern, err)
+	}
+	var paths []string
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if ok, _ := filepath.Match(pattern, d.Name()); ok {
+			paths = append(paths, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to walk %s: %w", dir, err)
+	}
+	return paths, nil
+}
+
+// VerifyDir verifies every config under dir matching opt.Pattern, at most
+// opt.Concurrency at a time, and prints a summary per config. It fails when
+// any config fails.
+func VerifyDir(ctx context.Context, dir string, opt VerifyOption) error {
+	if opt.DebugGoStruct {
+		return fmt.Errorf("--debug-go-struct cannot be combined with --dir")
+	}
+	switch opt.Output {
+	case "", "text", "json":
+	default:
+		return fmt.Errorf("unknown output format %q (expected text or json)", opt.Output)
+	}
+	pattern := opt.Pattern
+	if pattern == "" {
+		pattern = defaultVerifyPattern
+	}
+	paths, err := findConfigs(dir, pattern)
+	if err != nil {
+		return err
+	}
+	if len(paths) == 0 {
+		return fmt.Errorf("no config files matching %q under %s", pattern, dir)
+	}
+
+	concurrency := opt.Concurrency
+	if concurrency <= 0 {
+		concurrency = defaultVerifyConcurrency
+	}
+	sem := make(chan struct{}, concurrency)
+
+	reports := make([]verifyReport, len(paths))
+	var wg sync.WaitGroup
+	for i, path := range paths {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			select {
+			case sem <- struct{}{}:
+			case <-ctx.Done():
+				reports[i] = verifyReport{Config: path, Errors: []string{ctx.Err().Error()}, Warnings: []string{}}
+				return
+			}
+			defer func() { <-sem }()
+
+			app, err := New(ctx, path)
+			if err != nil {
+				reports[i] = failedVerifyReport(err)
+				reports[i].Config = path
+				return
+			}
+			reports[i], _ = app.buildVerifyReport(ctx)
+			reports[i].Config = path
+		}()
+	}
+	wg.Wait()
+
+	failed := 0
+	for _, r := range reports {
+		if !r.OK {
+			failed++
+		}
+	}
+	if opt.Output == "json" {
+		if err := printJSON(reports); err != nil {
+			return err
+		}
+	} else {
+		for _, r := range reports {
+			if r.OK {
+				fmt.Printf("OK: %s\n", r.Config)
+			} else {
+				fmt.Printf("NG: %s (%d error(s))\n", r.Config, len(r.Errors))
+			}
+			for _, e := range r.Errors {
+				fmt.Printf("  NG: %s\n", e)
+			}
+			for _, w := range r.Warnings {
+				fmt.Printf("  WARN: %s\n", w)
+			}
+		}
+		fmt.Printf("Verified %d config(s): %d failed\n", len(reports), failed)
+	}
+	if err := writeVerifyReport(opt.ReportFile, reports); err != nil {
+		return err
+	}
+	if failed > 0 {
+		return fmt.Errorf("verification failed for %d of %d config(s)", failed, len(reports))
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package batcha
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestVerifyDir(t *testing.T) {
+	dir := t.TempDir()
+	writeConfig := func(sub, name, jobDef string) string {
+		d := filepath.Join(dir, sub)
+		if err := os.MkdirAll(d, 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(d, "job.json"), []byte(jobDef), 0644); err != nil {
+			t.Fatal(err)
+		}
+		path := filepath.Join(d, name)
+		if err := os.WriteFile(path, []byte("region: us-east-1\njob_definition: job.json\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	valid := `{
+		"jobDefinitionName": "valid",
+		"type": "container",
+		"containerProperties": {
+			"image": "nginx",
+			"resourceRequirements": [{"type": "VCPU", "value": "1"}, {"type": "MEMORY", "value": "2048"}]
+		}
+	}`
+	okPath := writeConfig("a", "batcha.yml", valid)
+	ngPath := writeConfig("b", "batcha.yml", `{"jobDefinitionName": "broken", "type": "container"}`)
+	writeConfig("c", "other.yml", `{"jobDefinitionName": "ignored", "type": "container"}`)
+	writeConfig(".hidden", "batcha.yml", `{"jobDefinitionName": "hidden", "type": "container"}`)
+
+	var err error
+	out := captureStdout(t, func() {
+		err = VerifyDir(context.Background(), dir, VerifyOption{Pattern: "batcha.yml", Concurrency: 2})
+	})
+	if err == nil || !strings.Contains(err.Error(), "verification failed for 1 of 2 config(s)") {
+		t.Errorf("expected an aggregate failure, got: %v", err)
+	}
+	for _, want := range []string{
+		"OK: " + okPath,
+		"NG: " + ngPath,
+		"  NG: containerProperties is required",
+		"Verified 2 config(s): 1 failed",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("output missing %q:\n%s", want, out)
+		}
+	}
+	if strings.Contains(out, "other.yml") || strings.Contains(out, ".hidden") {
+		t.Errorf("unmatched or hidden configs should be skipped:\n%s", out)
+	}
+
+	out = captureStdout(t, func() {
+		err = VerifyDir(context.Background(), dir, VerifyOption{Pattern: "batcha.yml", Output: "json"})
+	})
+	var reports []verifyReport
+	if jerr := json.Unmarshal([]byte(out), &reports); jerr != nil {
+		t.Fatalf("output is not a JSON array: %v\n%s", jerr, out)
+	}
+	if err == nil || len(reports) != 2 || reports[0].Config != okPath || !reports[0].OK || reports[1].OK {
+		t.Errorf("unexpected JSON reports %+v (err %v)", reports, err)
+	}
+
+	if err := VerifyDir(context.Background(), filepath.Join(dir, "a"), VerifyOption{}); err != nil {
+		t.Errorf("expected a directory of valid configs to pass, got: %v", err)
+	}
+	if err := VerifyDir(context.Background(), dir, VerifyOption{Pattern: "*.yaml"}); err == nil {
+		t.Error("expected an error when no config matches")
+	}
+}